@@ -0,0 +1,142 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type ArchiveFormat int
+
+const (
+	ZipArchive ArchiveFormat = iota
+	TarGzArchive
+)
+
+//Every entry gets the same timestamp so that generating the same files
+//twice produces byte-for-byte identical archives. Zip can't represent
+//anything earlier than 1980.
+var ArchiveTimestamp time.Time = time.Date(1980, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type archiveEntry struct {
+	Path string
+	Body []byte
+	Mode os.FileMode
+}
+
+func WriteArchive(writer io.Writer, format ArchiveFormat, files []GeneratedFile) error {
+	entries, err := archiveEntries(files)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ZipArchive:
+		return writeZipArchive(writer, entries)
+	case TarGzArchive:
+		return writeTarGzArchive(writer, entries)
+	}
+	return errors.New("Unknown archive format")
+}
+
+func WriteZipArchive(writer io.Writer, files []GeneratedFile) error {
+	return WriteArchive(writer, ZipArchive, files)
+}
+
+func WriteTarGzArchive(writer io.Writer, files []GeneratedFile) error {
+	return WriteArchive(writer, TarGzArchive, files)
+}
+
+//archiveEntries sorts the files by path. When several files share a path the
+//last one wins, which matches what writing them to disk in order would do.
+func archiveEntries(files []GeneratedFile) ([]archiveEntry, error) {
+	entriesByPath := make(map[string]archiveEntry)
+	for _, file := range files {
+		relativePath, err := file.RelativePath()
+		if err != nil {
+			return []archiveEntry{}, err
+		}
+		body, err := file.DecodedBody()
+		if err != nil {
+			return []archiveEntry{}, err
+		}
+		mode := file.Mode.Perm()
+		if mode == 0 {
+			mode = DefaultFileMode
+		}
+		archivePath := filepath.ToSlash(relativePath)
+		entriesByPath[archivePath] = archiveEntry{Path: archivePath, Body: body, Mode: mode}
+	}
+
+	paths := make([]string, 0, len(entriesByPath))
+	for path := range entriesByPath {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	entries := make([]archiveEntry, 0, len(paths))
+	for _, path := range paths {
+		entries = append(entries, entriesByPath[path])
+	}
+	return entries, nil
+}
+
+func writeZipArchive(writer io.Writer, entries []archiveEntry) error {
+	zipWriter := zip.NewWriter(writer)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.Path, Method: zip.Deflate, Modified: ArchiveTimestamp}
+		header.SetMode(entry.Mode)
+		entryWriter, err := zipWriter.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		if _, err := entryWriter.Write(entry.Body); err != nil {
+			return err
+		}
+	}
+	return zipWriter.Close()
+}
+
+func writeTarGzArchive(writer io.Writer, entries []archiveEntry) error {
+	gzipWriter := gzip.NewWriter(writer)
+	gzipWriter.ModTime = ArchiveTimestamp
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, entry := range entries {
+		header := &tar.Header{
+			Name:     entry.Path,
+			Mode:     int64(entry.Mode),
+			Size:     int64(len(entry.Body)),
+			ModTime:  ArchiveTimestamp,
+			Typeflag: tar.TypeReg,
+		}
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := tarWriter.Write(entry.Body); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
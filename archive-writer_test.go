@@ -0,0 +1,106 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"testing"
+)
+
+var archiveTestFiles []GeneratedFile = []GeneratedFile{
+	GeneratedFile{FileName: "second.txt", Directory: "levo_gen", Body: []byte("Second")},
+	GeneratedFile{FileName: "first.txt", Directory: "levo_gen/subdir", Body: []byte("First"), Mode: 0755},
+	GeneratedFile{FileName: "second.txt", Directory: "levo_gen", Body: []byte("Second again")},
+}
+
+func TestWriteZipArchive(testing *testing.T) {
+	firstBuffer := bytes.NewBuffer([]byte{})
+	if err := WriteZipArchive(firstBuffer, archiveTestFiles); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	secondBuffer := bytes.NewBuffer([]byte{})
+	if err := WriteArchive(secondBuffer, ZipArchive, archiveTestFiles); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if !bytes.Equal(firstBuffer.Bytes(), secondBuffer.Bytes()) {
+		testing.Errorf("Zip archives of the same files are not identical")
+	}
+
+	reader, err := zip.NewReader(bytes.NewReader(firstBuffer.Bytes()), int64(firstBuffer.Len()))
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(reader.File) != 2 {
+		testing.Fatalf("Expecting %v entries. Got %v", 2, len(reader.File))
+	}
+	if reader.File[0].Name != "levo_gen/second.txt" {
+		testing.Errorf("Expecting entry %v. Got %v", "levo_gen/second.txt", reader.File[0].Name)
+	}
+	if reader.File[1].Name != "levo_gen/subdir/first.txt" {
+		testing.Errorf("Expecting entry %v. Got %v", "levo_gen/subdir/first.txt", reader.File[1].Name)
+	} else if reader.File[1].Mode().Perm() != 0755 {
+		testing.Errorf("Expecting mode %v. Got %v", 0755, reader.File[1].Mode().Perm())
+	}
+	entryReader, err := reader.File[0].Open()
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	defer entryReader.Close()
+	if contents, _ := ioutil.ReadAll(entryReader); string(contents) != "Second again" {
+		testing.Errorf("Expecting contents %v. Got %v", "Second again", string(contents))
+	}
+}
+
+func TestWriteTarGzArchive(testing *testing.T) {
+	firstBuffer := bytes.NewBuffer([]byte{})
+	if err := WriteTarGzArchive(firstBuffer, archiveTestFiles); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	secondBuffer := bytes.NewBuffer([]byte{})
+	if err := WriteArchive(secondBuffer, TarGzArchive, archiveTestFiles); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if !bytes.Equal(firstBuffer.Bytes(), secondBuffer.Bytes()) {
+		testing.Errorf("Tar archives of the same files are not identical")
+	}
+
+	gzipReader, err := gzip.NewReader(firstBuffer)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	tarReader := tar.NewReader(gzipReader)
+	names := make([]string, 0)
+	for {
+		header, err := tarReader.Next()
+		if err != nil {
+			break
+		}
+		if !header.ModTime.Equal(ArchiveTimestamp) {
+			testing.Errorf("Expecting timestamp %v. Got %v", ArchiveTimestamp, header.ModTime)
+		}
+		names = append(names, header.Name)
+	}
+	if len(names) != 2 || names[0] != "levo_gen/second.txt" || names[1] != "levo_gen/subdir/first.txt" {
+		testing.Errorf("Unexpected archive entries %v", names)
+	}
+
+	if err := WriteArchive(bytes.NewBuffer([]byte{}), TarGzArchive, []GeneratedFile{GeneratedFile{Directory: "../"}}); err == nil {
+		testing.Errorf("No error returned when archiving a file without a filename")
+	}
+}
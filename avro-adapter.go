@@ -0,0 +1,232 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+var avroPrimitiveTypes map[string]string = map[string]string{
+	"boolean": "boolean",
+	"int":     "int",
+	"long":    "long",
+	"float":   "float",
+	"double":  "float",
+	"bytes":   "byte",
+	"string":  "string",
+}
+
+var avroLogicalTypes map[string]string = map[string]string{
+	"date":                   "date",
+	"timestamp-millis":       "date",
+	"timestamp-micros":       "date",
+	"local-timestamp-millis": "date",
+	"local-timestamp-micros": "date",
+	"decimal":                "float",
+	"uuid":                   "string",
+}
+
+//AvroAdapter imports Avro schemas (.avsc). Records become models and enums
+//become Enums, named without their namespace; the namespace of the first
+//named type is the Project. Unions of null and one other type are
+//Nullable, fields with a default (which readers fill in when the data lacks
+//them) are Optional, arrays set IsSetType, fixed is "byte" and the date and
+//timestamp logical types are "date".
+type AvroAdapter struct{}
+
+func (self *AvroAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	fileContents, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.ParseAvroSchema(fileContents)
+}
+
+func (self *AvroAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	return self.ParseAvroSchema([]byte(schemaString))
+}
+
+//ParseAvroSchema accepts a single schema or an array of schemas.
+//Constructs such as maps that have no levo equivalent are listed in the
+//Unsupported field of the Schema built from the rest of the document.
+func (self *AvroAdapter) ParseAvroSchema(schemaJSON []byte) (Schema, error) {
+	document, err := decodeDocumentJSON(schemaJSON)
+	if err != nil {
+		return Schema{}, err
+	}
+	importer := &avroImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, Names: make(map[string]string)}
+	schemas, ok := document.([]interface{})
+	if !ok {
+		schemas = []interface{}{document}
+	}
+	for index, schema := range schemas {
+		if definition, ok := schema.(*documentObject); ok {
+			importer.resolveType(definition, "", "#"+strconv.Itoa(index))
+		}
+		//A bare primitive type declares nothing
+	}
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+type avroImporter struct {
+	Schema Schema
+	Names  map[string]string
+}
+
+//resolveType returns the levo type of an Avro type, whether it is an array
+//and whether it allows null, importing the records and enums it defines
+//along the way.
+func (self *avroImporter) resolveType(avroType interface{}, namespace string, path string) (string, bool, bool, bool) {
+	switch typedType := avroType.(type) {
+	case string:
+		if portableType, ok := avroPrimitiveTypes[typedType]; ok {
+			return portableType, false, false, true
+		}
+		if typedType == "null" {
+			self.unsupported(path, "null", "values that are always null")
+			return "", false, false, false
+		}
+		if name, ok := self.Names[avroFullName(typedType, namespace)]; ok {
+			return name, false, false, true
+		}
+		if name, ok := self.Names[typedType]; ok {
+			return name, false, false, true
+		}
+		self.unsupported(path, "type", "type "+typedType+" is not defined before it is used")
+		return "", false, false, false
+	case []interface{}:
+		//Unions
+		var alternative interface{}
+		count := 0
+		for _, value := range typedType {
+			if value == "null" {
+				continue
+			}
+			alternative = value
+			count++
+		}
+		if count != 1 {
+			self.unsupported(path, "union", "unions of more than one type besides null")
+			return "", false, false, false
+		}
+		propertyType, isSetType, _, ok := self.resolveType(alternative, namespace, path)
+		return propertyType, isSetType, len(typedType) > 1, ok
+	case *documentObject:
+		if logicalType, ok := avroLogicalTypes[typedType.String("logicalType")]; ok {
+			return logicalType, false, false, true
+		}
+		switch typedType.String("type") {
+		case "record", "error":
+			return self.importRecord(typedType, namespace), false, false, true
+		case "enum":
+			return self.importEnum(typedType, namespace), false, false, true
+		case "fixed":
+			self.Names[avroFullName(typedType.String("name"), avroNamespace(typedType, namespace))] = "byte"
+			return "byte", false, false, true
+		case "array":
+			itemType, itemIsSetType, _, ok := self.resolveType(typedType.Get("items"), namespace, path+"/items")
+			if ok && itemIsSetType {
+				self.unsupported(path, "array", "nested arrays")
+				return "", false, false, false
+			}
+			return itemType, true, false, ok
+		case "map":
+			self.unsupported(path, "map", "properties can't be maps")
+			return "", false, false, false
+		}
+		//A primitive type written as an object
+		return self.resolveType(typedType.Get("type"), namespace, path)
+	}
+	self.unsupported(path, "type", "unrecognised type")
+	return "", false, false, false
+}
+
+func (self *avroImporter) importRecord(record *documentObject, namespace string) string {
+	namespace = avroNamespace(record, namespace)
+	name := avroShortName(record.String("name"))
+	if self.Schema.Project == "" {
+		self.Schema.Project = namespace
+	}
+	//Registered first, since records may refer to themselves
+	self.Names[avroFullName(record.String("name"), namespace)] = name
+	index := len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, Model{Name: name})
+	model := Model{Name: name, Properties: make([]ModelProperty, 0)}
+
+	fields, _ := record.Get("fields").([]interface{})
+	for _, value := range fields {
+		field, ok := value.(*documentObject)
+		if !ok {
+			continue
+		}
+		fieldName := field.String("name")
+		fieldPath := name + "." + fieldName
+		propertyType, isSetType, nullable, ok := self.resolveType(field.Get("type"), namespace, fieldPath)
+		if !ok {
+			continue
+		}
+		model.Properties = append(model.Properties, ModelProperty{RemoteIdentifier: fieldName, LocalIdentifier: fieldName, PropertyType: propertyType, IsSetType: isSetType, Optional: field.Has("default"), Nullable: nullable})
+	}
+	self.Schema.Models[index] = model
+	return name
+}
+
+func (self *avroImporter) importEnum(definition *documentObject, namespace string) string {
+	namespace = avroNamespace(definition, namespace)
+	name := avroShortName(definition.String("name"))
+	if self.Schema.Project == "" {
+		self.Schema.Project = namespace
+	}
+	self.Names[avroFullName(definition.String("name"), namespace)] = name
+	enum := Enum{Name: name, Type: "string", Values: make([]EnumValue, 0)}
+	for _, symbol := range definition.Strings("symbols") {
+		enum.Values = append(enum.Values, EnumValue{Name: symbol, RawValue: symbol})
+	}
+	self.Schema.Enums = append(self.Schema.Enums, enum)
+	return name
+}
+
+func (self *avroImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+//avroNamespace returns the namespace a named type declares, explicitly or
+//in a dotted name, or the enclosing one.
+func avroNamespace(definition *documentObject, enclosingNamespace string) string {
+	name := definition.String("name")
+	if lastDot := strings.LastIndex(name, "."); lastDot >= 0 {
+		return name[:lastDot]
+	}
+	if definition.Has("namespace") {
+		return definition.String("namespace")
+	}
+	return enclosingNamespace
+}
+
+func avroFullName(name string, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func avroShortName(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
@@ -0,0 +1,79 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessAvroFile(testing *testing.T) {
+	adapter := GetAvroAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/schema.avsc")
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "Employee.attributes" {
+		testing.Errorf("Expecting the map field Employee.attributes to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if schema.Project != "com.example.hr" {
+		testing.Errorf("Expecting project %v. Got %v", "com.example.hr", schema.Project)
+	}
+
+	expectedModels := []Model{
+		{Name: "Employee", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long"},
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"},
+			{RemoteIdentifier: "email", LocalIdentifier: "email", PropertyType: "string", Optional: true, Nullable: true},
+			{RemoteIdentifier: "hired", LocalIdentifier: "hired", PropertyType: "date"},
+			{RemoteIdentifier: "salary", LocalIdentifier: "salary", PropertyType: "float"},
+			{RemoteIdentifier: "role", LocalIdentifier: "role", PropertyType: "Role"},
+			{RemoteIdentifier: "address", LocalIdentifier: "address", PropertyType: "Address"},
+			{RemoteIdentifier: "previous_addresses", LocalIdentifier: "previous_addresses", PropertyType: "Address", IsSetType: true},
+			{RemoteIdentifier: "manager", LocalIdentifier: "manager", PropertyType: "Employee", Nullable: true},
+			{RemoteIdentifier: "skills", LocalIdentifier: "skills", PropertyType: "string", IsSetType: true, Nullable: true},
+		}},
+		{Name: "Address", Properties: []ModelProperty{
+			{RemoteIdentifier: "street", LocalIdentifier: "street", PropertyType: "string"},
+			{RemoteIdentifier: "city", LocalIdentifier: "city", PropertyType: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	expectedEnums := []Enum{{Name: "Role", Type: "string", Values: []EnumValue{{Name: "ENGINEER", RawValue: "ENGINEER"}, {Name: "MANAGER", RawValue: "MANAGER"}}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting enums %v. Got %v", expectedEnums, schema.Enums)
+	}
+}
+
+func TestProcessAvroString(testing *testing.T) {
+	adapter := GetAvroAdapter()
+	schema, err := adapter.ProcessSchemaString(`[
+		{"type": "enum", "name": "shapes.Kind", "symbols": ["CIRCLE", "SQUARE"]},
+		{"type": "record", "name": "Shape", "namespace": "shapes", "fields": [
+			{"name": "kind", "type": "Kind"},
+			{"name": "size", "type": ["int", "float"]}
+		]}
+	]`)
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Keyword != "union" {
+		testing.Errorf("Expecting the int or float union to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) != 1 || !reflect.DeepEqual(schema.Models[0].Properties, []ModelProperty{{RemoteIdentifier: "kind", LocalIdentifier: "kind", PropertyType: "Kind"}}) {
+		testing.Errorf("Expecting model Shape with a Kind property. Got %v", schema.Models)
+	}
+
+	if _, err := adapter.ProcessSchemaString(`{"type": "record"`); err == nil {
+		testing.Errorf("Parsing invalid JSON did not fail")
+	}
+}
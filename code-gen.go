@@ -16,10 +16,12 @@ package levo
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"sync"
 )
 
 const LibraryVersion string = "1.0.0"
@@ -29,6 +31,7 @@ type TemplateData struct {
 	ProjectName string
 	PackagePath string
 	Models      []Model
+	Enums       []Enum
 	Features    map[string]bool
 }
 
@@ -43,59 +46,302 @@ type ConfigurationAdapter interface {
 	ProcessConfigurationString(configString string) (Context, error)
 }
 
+//ContextOutputAdapter is implemented by output adapters that can abandon
+//a template execution when its context.Context is done.
+type ContextOutputAdapter interface {
+	OutputAdapter
+	GenerateFilesContext(ctx gocontext.Context, templateInfo TemplateInfo, templateData TemplateData) ([]GeneratedFile, error)
+}
+
 type SchemaAdapter interface {
 	ProcessSchemaFile(schemaFile string) (Schema, error)
 	ProcessSchemaString(schemaString string) (Schema, error)
 }
 
+type generationTask struct {
+	MappingIndex int
+	Template     *TemplateInfo
+	Models       []Model
+}
+
+//ErrGenerationCancelled is returned, wrapping the context's own error, when
+//ProcessMappingsContext stops because its context.Context is done.
+var ErrGenerationCancelled = errors.New("Generation cancelled")
+
 func ProcessMappings(context Context) ([]GeneratedFile, error) {
+	return ProcessMappingsContext(gocontext.Background(), context)
+}
+
+//ProcessMappingsContext is ProcessMappings with cancellation. Generation
+//stops between templates, and running templates are abandoned, once ctx is
+//cancelled or its deadline passes. The error returned then satisfies
+//errors.Is for both ErrGenerationCancelled and ctx.Err().
+func ProcessMappingsContext(ctx gocontext.Context, context Context) ([]GeneratedFile, error) {
 	if len(context.Mappings) == 0 {
 		return []GeneratedFile{}, errors.New("No mappings to process")
 	}
+	if err := context.checkFeatureRequirements(); err != nil {
+		return []GeneratedFile{}, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return []GeneratedFile{}, cancellationError(ctx)
+	}
+	unparsedTemplates, errs := parseTemplates(context)
+	if len(errs) > 0 && !context.CollectErrors {
+		return []GeneratedFile{}, errs[0]
+	}
 
-	//Pre-parse all the templates.
-	//For Go templates this will compile them all into one associated
-	//set. This way templates can reference eachother.
+	tasks := make([]generationTask, 0)
+	for _, task := range generationTasks(context) {
+		//Templates that failed to parse have already been reported
+		if !unparsedTemplates[templatePath(task.Template)] {
+			tasks = append(tasks, task)
+		}
+	}
+
+	results := runGenerationTasks(ctx, context, tasks)
+	generatedFiles := make([]GeneratedFile, 0, 0)
+	for _, result := range results {
+		if result.Err != nil {
+			if !context.CollectErrors {
+				return []GeneratedFile{}, result.Err
+			}
+			errs = append(errs, result.Err)
+			if errors.Is(result.Err, ErrGenerationCancelled) {
+				//Every remaining task fails the same way
+				break
+			}
+			continue
+		}
+		for _, newestFile := range result.Files {
+			generatedFiles = append(generatedFiles, newestFile)
+		}
+	}
+	if len(errs) > 0 {
+		return generatedFiles, errors.Join(errs...)
+	}
+	return generatedFiles, nil
+}
+
+//GenerationErrors lists every GenerationError in err, including each of the
+//errors joined together when Context.CollectErrors is set.
+func GenerationErrors(err error) []*GenerationError {
+	generationErrors := make([]*GenerationError, 0)
+	if err == nil {
+		return generationErrors
+	}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, joinedErr := range joined.Unwrap() {
+			generationErrors = append(generationErrors, GenerationErrors(joinedErr)...)
+		}
+		return generationErrors
+	}
+	var generationError *GenerationError
+	if errors.As(err, &generationError) {
+		generationErrors = append(generationErrors, generationError)
+	}
+	return generationErrors
+}
+
+type generationResult struct {
+	Files   []GeneratedFile
+	Err     error
+	Skipped bool
+}
+
+//runGenerationTasks executes the tasks and returns their results in task
+//order. With Context.Concurrency above one the tasks run on that many
+//goroutines. Unless Context.CollectErrors is set, the tasks after the first
+//failure are skipped.
+func runGenerationTasks(ctx gocontext.Context, context Context, tasks []generationTask) []generationResult {
+	results := make([]generationResult, len(tasks))
+	workers := context.Concurrency
+	if workers > len(tasks) {
+		workers = len(tasks)
+	}
+	if workers <= 1 {
+		for index, task := range tasks {
+			results[index] = runGenerationTask(ctx, context, task)
+			if results[index].Err != nil && !context.CollectErrors {
+				for skipped := index + 1; skipped < len(tasks); skipped++ {
+					results[skipped].Skipped = true
+				}
+				break
+			}
+		}
+		return results
+	}
+
+	for _, registry := range typeRegistries(context) {
+		registry.freeze()
+		defer registry.thaw()
+	}
+
+	//Tasks after the earliest failure are skipped. Tasks before it always
+	//run, so the failure reported matches a sequential run.
+	firstFailure := len(tasks)
+	var failureLock sync.Mutex
+	taskIndexes := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for index := range taskIndexes {
+				failureLock.Lock()
+				skip := index > firstFailure
+				failureLock.Unlock()
+				if skip {
+					results[index].Skipped = true
+					continue
+				}
+				results[index] = runGenerationTask(ctx, context, tasks[index])
+				if results[index].Err != nil && !context.CollectErrors {
+					failureLock.Lock()
+					if index < firstFailure {
+						firstFailure = index
+					}
+					failureLock.Unlock()
+				}
+			}
+		}()
+	}
+	for index := range tasks {
+		taskIndexes <- index
+	}
+	close(taskIndexes)
+	waitGroup.Wait()
+	return results
+}
+
+//typeRegistries returns the TypeRegistries the templates of a context can
+//change, which may appear more than once.
+func typeRegistries(context Context) []*TypeRegistry {
+	registries := make([]*TypeRegistry, 0)
+	if context.TypeRegistry != nil {
+		registries = append(registries, context.TypeRegistry)
+	}
 	for _, templateInfo := range context.Templates {
+		if goAdapter, ok := templateInfo.Adapter.(*GoTemplateAdapter); ok && goAdapter.TypeRegistry != nil {
+			registries = append(registries, goAdapter.TypeRegistry)
+		}
+	}
+	return registries
+}
+
+//Pre-parse all the templates.
+//For Go templates this will compile them all into one associated
+//set. This way templates can reference eachother.
+//Parsing stops at the first failure unless Context.CollectErrors is set.
+//The paths of the templates that failed are returned with their errors.
+func parseTemplates(context Context) (map[string]bool, []error) {
+	unparsedTemplates := make(map[string]bool)
+	errs := make([]error, 0)
+	for index, templateInfo := range context.Templates {
 		err := templateInfo.Adapter.ParseTemplate(templateInfo)
 		if err != nil {
-			return []GeneratedFile{}, err
+			unparsedTemplates[templatePath(&context.Templates[index])] = true
+			errs = append(errs, err)
+			if !context.CollectErrors {
+				break
+			}
 		}
 	}
+	return unparsedTemplates, errs
+}
 
-	generatedFiles := make([]GeneratedFile, 0, 0)
-	for _, mapping := range context.Mappings {
+//generationTasks flattens the mappings into the template executions
+//ProcessMappings performs, in the order it performs them.
+func generationTasks(context Context) []generationTask {
+	tasks := make([]generationTask, 0)
+	for mappingIndex, mapping := range context.Mappings {
 		templateModels := make([]Model, 0, 0)
 		for _, model := range mapping.Models {
 			templateModels = append(templateModels, *model)
 		}
 		for _, templateInfo := range mapping.Templates {
-			newestFiles, err := processTemplate(templateInfo, context, templateModels)
-			if err != nil {
-				return []GeneratedFile{}, err
-			}
-			for _, newestFile := range newestFiles {
-				generatedFiles = append(generatedFiles, newestFile)
-			}
+			tasks = append(tasks, generationTask{MappingIndex: mappingIndex, Template: templateInfo, Models: templateModels})
 		}
 	}
-	return generatedFiles, nil
+	return tasks
+}
+
+func runGenerationTask(ctx gocontext.Context, context Context, task generationTask) generationResult {
+	if ctx.Err() != nil {
+		return generationResult{Err: cancellationError(ctx)}
+	}
+	files, err := processTemplateContext(ctx, task.Template, context, task.Models)
+	if err != nil {
+		return generationResult{Files: files, Err: annotateGenerationError(err, task)}
+	}
+	return generationResult{Files: files}
 }
 
 func processTemplate(templateInfo *TemplateInfo, context Context, templateModels []Model) ([]GeneratedFile, error) {
+	return processTemplateContext(gocontext.Background(), templateInfo, context, templateModels)
+}
+
+func processTemplateContext(ctx gocontext.Context, templateInfo *TemplateInfo, context Context, templateModels []Model) ([]GeneratedFile, error) {
 	if strings.HasSuffix(strings.ToLower(templateInfo.FileName), ".lt") == false {
 		//The template is not a Levo template (.lt)
 		//Treat it like a static binary file
 		return getBinaryFiles(templateInfo)
 	} else {
-		templateData := TemplateData{PackageName: context.PackageName, ProjectName: context.ProjectName}
-		templateData.PackagePath = strings.Replace(context.PackageName, ".", "/", -1)
-		templateData.Models = templateModels
-		templateData.Features = context.TemplateFeatures
-		return templateInfo.Adapter.GenerateFiles(*templateInfo, templateData)
+		templateData := templateDataFor(context, templateModels)
+		if goAdapter, ok := templateInfo.Adapter.(*GoTemplateAdapter); ok {
+			return goAdapter.generateFilesContext(ctx, *templateInfo, templateData)
+		}
+		if contextAdapter, ok := templateInfo.Adapter.(ContextOutputAdapter); ok {
+			return contextAdapter.GenerateFilesContext(ctx, *templateInfo, templateData)
+		}
+		//Adapters without GenerateFilesContext can't be stopped
+		return generateFilesUntilDone(ctx, func(gocontext.Context) ([]GeneratedFile, error) {
+			return templateInfo.Adapter.GenerateFiles(*templateInfo, templateData)
+		})
+	}
+}
+
+//generateFilesUntilDone runs generate on its own goroutine and gives up
+//waiting for it once ctx is done. generate is passed a context that is
+//cancelled when generateFilesUntilDone returns, so that it can stop too,
+//and its result is buffered so that a late one never blocks it.
+func generateFilesUntilDone(ctx gocontext.Context, generate func(gocontext.Context) ([]GeneratedFile, error)) ([]GeneratedFile, error) {
+	if ctx.Done() == nil {
+		return generate(ctx)
+	}
+	ctx, cancel := gocontext.WithCancel(ctx)
+	defer cancel()
+	type generated struct {
+		Files []GeneratedFile
+		Err   error
+	}
+	done := make(chan generated, 1)
+	go func() {
+		files, err := generate(ctx)
+		done <- generated{Files: files, Err: err}
+	}()
+	select {
+	case result := <-done:
+		return result.Files, result.Err
+	case <-ctx.Done():
+		return []GeneratedFile{}, cancellationError(ctx)
 	}
 }
 
+func cancellationError(ctx gocontext.Context) error {
+	return fmt.Errorf("%w: %w", ErrGenerationCancelled, ctx.Err())
+}
+
+func templateDataFor(context Context, templateModels []Model) TemplateData {
+	templateData := TemplateData{PackageName: context.PackageName, ProjectName: context.ProjectName}
+	templateData.PackagePath = strings.Replace(context.PackageName, ".", "/", -1)
+	templateData.Models = context.Schema.withParentRefs(templateModels)
+	templateData.Enums = context.Schema.Enums
+	templateData.Features = context.TemplateFeatures
+	return templateData
+}
+
 func getBinaryFiles(templateInfo *TemplateInfo) ([]GeneratedFile, error) {
 	bodyBuffer := bytes.NewBuffer(templateInfo.Body)
 	filesForTemplate, err := templateInfo.Adapter.GetFilesFromOutput(bodyBuffer, templateInfo.Directory)
@@ -103,17 +349,57 @@ func getBinaryFiles(templateInfo *TemplateInfo) ([]GeneratedFile, error) {
 		return []GeneratedFile{}, err
 	}
 	if len(filesForTemplate) > 0 {
+		for index := range filesForTemplate {
+			filesForTemplate[index].Mode = templateInfo.Mode
+		}
 		return filesForTemplate, nil
 	}
-	newFile := GeneratedFile{FileName: templateInfo.FileName, Directory: templateInfo.Directory, Body: templateInfo.Body}
+	newFile := GeneratedFile{FileName: templateInfo.FileName, Directory: templateInfo.Directory, Body: templateInfo.Body, Mode: templateInfo.Mode}
 	return []GeneratedFile{newFile}, nil
 }
 
 func BeginContext() Context {
 	fmt.Printf("")
-	return Context{PackageName: "com.example", ProjectName: "ExampleProject", TemplaterVersion: LibraryVersion, GoAdapter: GoTemplateAdapter{}, TemplateFeatures: make(map[string]bool, 0)}
+	typeRegistry := GetTypeRegistry()
+	return Context{PackageName: "com.example", ProjectName: "ExampleProject", TemplaterVersion: LibraryVersion, GoAdapter: GoTemplateAdapter{TypeRegistry: typeRegistry}, TypeRegistry: typeRegistry, TemplateFeatures: make(map[string]bool, 0)}
 }
 
 func GetJSONSchemaAdapter() JSONSchemaAdapter {
 	return JSONSchemaAdapter{}
 }
+
+func GetJSONConfigurationAdapter() JSONConfigAdapter {
+	return JSONConfigAdapter{}
+}
+
+func GetJSONSchemaImportAdapter() JSONSchemaImportAdapter {
+	return JSONSchemaImportAdapter{}
+}
+
+func GetOpenAPIAdapter() OpenAPIAdapter {
+	return OpenAPIAdapter{}
+}
+
+func GetProtoAdapter() ProtoAdapter {
+	return ProtoAdapter{}
+}
+
+func GetGraphQLAdapter() GraphQLAdapter {
+	return GraphQLAdapter{}
+}
+
+func GetSQLSchemaAdapter() SQLSchemaAdapter {
+	return SQLSchemaAdapter{}
+}
+
+func GetJSONSampleAdapter(rootModelName string) JSONSampleAdapter {
+	return JSONSampleAdapter{RootModelName: rootModelName}
+}
+
+func GetGoStructAdapter() GoStructAdapter {
+	return GoStructAdapter{}
+}
+
+func GetAvroAdapter() AvroAdapter {
+	return AvroAdapter{}
+}
@@ -15,8 +15,12 @@ limitations under the License.*/
 package levo
 
 import (
+	gocontext "context"
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 )
 
 const ExpectedBody string = "package TestPackage01.models;"
@@ -67,3 +71,108 @@ func TestProcessTemplate(testing *testing.T) {
 		testing.Errorf("Expecting %v templates. Got %v", 1, len(generatedFiles))
 	}
 }
+
+func TestProcessMappingsConcurrently(testing *testing.T) {
+	SetupContext()
+	SetupTemplate()
+	context.AddTemplate("Model.lt", []byte("<<levo filename:{{(index .Models 0).Name}}.generic>>\nclass {{(index .Models 0).Name}}\n<<levo>>\n"), testTemplaterVersion, "template", &context.GoAdapter)
+	for index := 0; index < 20; index++ {
+		name := fmt.Sprintf("Model%02d", index)
+		context.AddModelWithName(name)
+		context.AddTemplatesForModelsMapping([]string{"Model.lt", templateFileName}, []string{name})
+	}
+
+	sequentialFiles, err := ProcessMappings(context)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	context.Concurrency = 4
+	concurrentFiles, err := ProcessMappings(context)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if !reflect.DeepEqual(sequentialFiles, concurrentFiles) {
+		testing.Errorf("Concurrent output differs from sequential output")
+	}
+
+	//The first failing template in mapping order is the one reported
+	context.AddTemplate("Broken.lt", []byte("{{template \"missing\"}}"), testTemplaterVersion, "template", &context.GoAdapter)
+	context.AddTemplate("AlsoBroken.lt", []byte("{{template \"absent\"}}"), testTemplaterVersion, "template", &context.GoAdapter)
+	context.AddTemplatesForModelsMapping([]string{"Broken.lt"}, []string{"Model05"})
+	context.AddTemplatesForModelsMapping([]string{"AlsoBroken.lt"}, []string{"Model15"})
+	for attempt := 0; attempt < 10; attempt++ {
+		_, err = ProcessMappings(context)
+		var generationError *GenerationError
+		if !errors.As(err, &generationError) {
+			testing.Fatalf("Expecting a GenerationError. Got %v", err)
+		}
+		if generationError.TemplatePath != "template/Broken.lt" || generationError.ModelName != "Model05" {
+			testing.Errorf("Expecting %v for %v. Got %v for %v", "template/Broken.lt", "Model05", generationError.TemplatePath, generationError.ModelName)
+		}
+	}
+}
+
+func TestProcessMappingsContext(testing *testing.T) {
+	SetupContext()
+	SetupTemplate()
+	context.AddTemplate("Slow.lt", []byte("<<levo filename:slow.txt>>\n{{range .Models}}{{range $.Models}}{{range $.Models}}{{.Name}}{{end}}{{end}}{{end}}\n<<levo>>\n"), testTemplaterVersion, "template", &context.GoAdapter)
+	modelNames := make([]string, 0)
+	for index := 0; index < 1000; index++ {
+		name := fmt.Sprintf("Model%04d", index)
+		context.AddModelWithName(name)
+		modelNames = append(modelNames, name)
+	}
+	context.AddTemplatesForModelsMapping([]string{templateFileName}, modelNames[:1])
+	context.AddTemplatesForModelsMapping([]string{"Slow.lt"}, modelNames)
+
+	//An already cancelled context generates nothing
+	cancelledContext, cancel := gocontext.WithCancel(gocontext.Background())
+	cancel()
+	_, err := ProcessMappingsContext(cancelledContext, context)
+	if !errors.Is(err, ErrGenerationCancelled) || !errors.Is(err, gocontext.Canceled) {
+		testing.Errorf("Expecting a cancellation error. Got %v", err)
+	}
+
+	//A template that runs past the deadline is abandoned
+	timeoutContext, cancel := gocontext.WithTimeout(gocontext.Background(), 50*time.Millisecond)
+	defer cancel()
+	started := time.Now()
+	_, err = ProcessMappingsContext(timeoutContext, context)
+	if !errors.Is(err, ErrGenerationCancelled) || !errors.Is(err, gocontext.DeadlineExceeded) {
+		testing.Errorf("Expecting a deadline error. Got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > 5*time.Second {
+		testing.Errorf("Generation took %v to stop after its deadline", elapsed)
+	}
+}
+
+func TestGenerateFilesUntilDoneStopsGenerate(testing *testing.T) {
+	timeoutContext, cancel := gocontext.WithTimeout(gocontext.Background(), 10*time.Millisecond)
+	defer cancel()
+	stopped := make(chan error, 1)
+	_, err := generateFilesUntilDone(timeoutContext, func(ctx gocontext.Context) ([]GeneratedFile, error) {
+		<-ctx.Done()
+		stopped <- ctx.Err()
+		return []GeneratedFile{}, ctx.Err()
+	})
+	if !errors.Is(err, ErrGenerationCancelled) {
+		testing.Errorf("Expecting a cancellation error. Got %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		testing.Errorf("Abandoned generate was not stopped")
+	}
+
+	//generate can't outlive the call, even when it finishes first
+	openContext, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	var generateContext gocontext.Context
+	generateFilesUntilDone(openContext, func(ctx gocontext.Context) ([]GeneratedFile, error) {
+		generateContext = ctx
+		return []GeneratedFile{}, nil
+	})
+	if generateContext == nil || generateContext.Err() == nil {
+		testing.Errorf("Expecting the context passed to generate to be cancelled")
+	}
+}
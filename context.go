@@ -16,14 +16,20 @@ package levo
 
 import (
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 )
 
+const base64BodyPrefix string = "<<levobase64>>"
+
 type Context struct {
 	ProjectName      string
 	PackageName      string
@@ -34,25 +40,111 @@ type Context struct {
 	Language         string
 	TemplateFeatures map[string]bool
 	GoAdapter        GoTemplateAdapter
+	TypeRegistry     *TypeRegistry
+	Concurrency      int
+	CollectErrors    bool
 }
 
+//Unsupported lists the parts of an imported schema that have no levo
+//equivalent. Each is left out of Models and Enums, or imported as the
+//closest thing levo has.
 type Schema struct {
-	Project string
-	Models  []Model
+	Project     string
+	Models      []Model
+	Enums       []Enum
+	Unsupported []UnsupportedConstruct `json:"-"`
 }
 
+//PrimaryKey lists the RemoteIdentifiers of the properties identifying a
+//model, in order. Models without one inherit their parent's, or fall back
+//on a property named id. The models templates receive have ParentRef set.
 type Model struct {
+	Name        string
+	Parent      string
+	ParentRef   *Model `json:"-"`
+	Properties  []ModelProperty
+	PrimaryKey  []string
+	ForeignKeys []ForeignKey
+	Indexes     []Index
+}
+
+//An Index covers Properties of a model, by RemoteIdentifier. Unique indexes
+//are unique constraints. Name is optional.
+type Index struct {
 	Name       string
-	Parent     string
-	ParentRef  *Model
-	Properties []ModelProperty
+	Properties []string
+	Unique     bool
 }
 
+//A ForeignKey links Properties of a model, by RemoteIdentifier, to
+//ReferencedProperties of the model named ReferencedModel.
+type ForeignKey struct {
+	Properties           []string
+	ReferencedModel      string
+	ReferencedProperties []string
+}
+
+//Optional properties may be missing from a payload and Nullable ones may
+//be null. NotNull properties are declared never to be null, which can't be
+//combined with Nullable. Default is the value a property takes when it is
+//missing; its Go type follows PropertyType: int64 for int, integer, short
+//and long, float64 for float, bool for boolean, and string for everything
+//else, including enum value names.
 type ModelProperty struct {
 	RemoteIdentifier string
 	LocalIdentifier  string
 	PropertyType     string
 	IsSetType        bool
+	Optional         bool
+	Nullable         bool
+	NotNull          bool
+	Default          interface{}
+	Relationship     Relationship
+}
+
+const (
+	HasOne    string = "hasOne"
+	HasMany   string = "hasMany"
+	BelongsTo string = "belongsTo"
+)
+
+const (
+	DeleteNullify  string = "nullify"
+	DeleteCascade  string = "cascade"
+	DeleteDeny     string = "deny"
+	DeleteNoAction string = "noAction"
+)
+
+//A Relationship makes a property whose PropertyType names another model an
+//association with that model. Kind is HasOne, HasMany (which must also be
+//IsSetType) or BelongsTo; an empty Kind means the property is not a
+//relationship. Inverse is the RemoteIdentifier of the property on the
+//other model that points back at this one. OnDelete says what happens to
+//the related objects when this one is deleted; a model owns the objects of
+//its DeleteCascade relationships. ForeignKey is the RemoteIdentifier of the
+//property holding the key of a BelongsTo relationship, when the model has
+//one.
+type Relationship struct {
+	Kind       string
+	Inverse    string
+	OnDelete   string
+	ForeignKey string
+}
+
+//An Enum is a named set of values. Type is the portable type of the raw
+//values: "string" (the default), "int" or "float". A property whose
+//PropertyType is the Name of an Enum holds one of its values.
+type Enum struct {
+	Name   string
+	Type   string
+	Values []EnumValue
+}
+
+//RawValue is optional. Without one, a value of a string enum is its Name
+//and a value of an int enum is its position in Values.
+type EnumValue struct {
+	Name     string
+	RawValue string
 }
 
 type TemplateInfo struct {
@@ -61,6 +153,7 @@ type TemplateInfo struct {
 	Directory string
 	FileName  string
 	Body      []byte
+	Mode      os.FileMode
 	Adapter   OutputAdapter
 }
 
@@ -73,6 +166,7 @@ type GeneratedFile struct {
 	FileName  string
 	Directory string
 	Body      []byte
+	Mode      os.FileMode
 }
 
 func (context *Context) AddModel(model Model) (*Model, error) {
@@ -98,6 +192,20 @@ func (context *Context) AddModelWithName(name string) (*Model, error) {
 	return context.AddModel(model)
 }
 
+func (context *Context) AddEnum(enum Enum) (*Enum, error) {
+	if enum.Name == "" {
+		return &Enum{}, errors.New("Enum name must not be empty string")
+	}
+	if _, err := context.EnumForName(enum.Name); err == nil {
+		return &Enum{}, errors.New("Attempted to add duplicate enum with name " + enum.Name)
+	}
+	if enum.Type == "" {
+		enum.Type = "string"
+	}
+	context.Schema.Enums = append(context.Schema.Enums, enum)
+	return &(context.Schema.Enums[len(context.Schema.Enums)-1]), nil
+}
+
 func (context *Context) AddTemplateDirectory(templateDirPath string) ([]TemplateInfo, error) {
 	err := filepath.Walk(templateDirPath, context.AddTemplateFile)
 	if err != nil {
@@ -135,6 +243,8 @@ func (context *Context) AddTemplateFilePath(filePath string) (TemplateInfo, erro
 	if err != nil {
 		return TemplateInfo{}, err
 	}
+	templateInfo.Mode = fileInfo.Mode().Perm()
+	context.Templates[len(context.Templates)-1].Mode = templateInfo.Mode
 
 	return *templateInfo, nil
 }
@@ -168,6 +278,7 @@ func (context *Context) AddTemplateFile(path string, info os.FileInfo, err error
 		if err != nil {
 			return err
 		}
+		context.Templates[len(context.Templates)-1].Mode = info.Mode().Perm()
 	}
 	return nil
 }
@@ -180,7 +291,7 @@ func (context *Context) AddTemplate(fileName string, body []byte, version string
 	if strings.HasSuffix(fileName, ".lt") == false {
 		encodedBody := make([]byte, base64.StdEncoding.EncodedLen(len(body)))
 		base64.StdEncoding.Encode(encodedBody, body)
-		prefix := []byte("<<levobase64>>")
+		prefix := []byte(base64BodyPrefix)
 		body = append(prefix, encodedBody...)
 		version = LibraryVersion
 	}
@@ -225,6 +336,15 @@ func (context *Context) ModelForName(name string) (*Model, error) {
 	return &Model{}, errors.New("Model not found: " + name)
 }
 
+func (context *Context) EnumForName(name string) (*Enum, error) {
+	for _, enum := range context.Schema.Enums {
+		if enum.Name == name {
+			return &enum, nil
+		}
+	}
+	return &Enum{}, errors.New("Enum not found: " + name)
+}
+
 func (context *Context) AddTemplatesForModelsMapping(templateFileNames []string, modelNames []string) error {
 	templates := make([]*TemplateInfo, 0)
 	models := make([]*Model, 0)
@@ -253,6 +373,23 @@ func (context *Context) AddTemplatesForModelsMapping(templateFileNames []string,
 	return nil
 }
 
+//Templates that rely on every mapped model having a primary key can say
+//so with this feature. ProcessMappings then rejects models without one.
+const FeaturePrimaryKeys string = "primarykeys"
+
+func (context *Context) checkFeatureRequirements() error {
+	if context.TemplateFeatures[FeaturePrimaryKeys] {
+		for _, mapping := range context.Mappings {
+			for _, model := range mapping.Models {
+				if len(context.Schema.primaryKey(*model)) == 0 {
+					return errors.New("Model " + model.Name + " has no PrimaryKey, which the " + FeaturePrimaryKeys + " feature requires")
+				}
+			}
+		}
+	}
+	return nil
+}
+
 func (context *Context) AddTemplateFeature(feature string) {
 	context.TemplateFeatures[strings.ToLower(feature)] = true
 }
@@ -261,6 +398,14 @@ func (context *Context) RemoveTemplateFeature(feature string) {
 	context.TemplateFeatures[strings.ToLower(feature)] = false
 }
 
+func (context *Context) AddCustomType(customType string, mappings map[string]string) {
+	if context.TypeRegistry == nil {
+		context.TypeRegistry = GetTypeRegistry()
+		context.GoAdapter.TypeRegistry = context.TypeRegistry
+	}
+	context.TypeRegistry.SetCustomTypes(customType, mappings)
+}
+
 func (model *Model) AddProperty(remoteIdentifier string, localIdentifier string, propertyType string) (*ModelProperty, error) {
 	if remoteIdentifier == "" && localIdentifier == "" {
 		return &ModelProperty{}, errors.New("Properties must have an identifier")
@@ -293,16 +438,441 @@ func (self *Schema) validate() error {
 		if model.Name == "" {
 			return errors.New("At least one model missing Remote Identifier")
 		}
+		if err := self.validateKeys(model); err != nil {
+			return err
+		}
 		for _, property := range model.Properties {
 			if property.RemoteIdentifier == "" {
 				return errors.New("Model " + model.Name + " has at least one property missing it's Remote Identifier. Type: " + property.PropertyType)
 			}
+			if err := self.validateRelationship(model, property); err != nil {
+				return err
+			}
+			if property.Nullable && property.NotNull {
+				return errors.New("Property " + property.RemoteIdentifier + " of model " + model.Name + " can't be both Nullable and NotNull")
+			}
+			if err := self.validateDefault(model, property); err != nil {
+				return err
+			}
+			//Type lookups are case sensitive for enums, so a near miss
+			//would silently be treated as an unknown type
+			if enum, ok := self.enumForType(property.PropertyType); ok && enum.Name != property.PropertyType {
+				return errors.New("Property " + property.RemoteIdentifier + " of model " + model.Name + " has type " + property.PropertyType + ". Did you mean enum " + enum.Name + "?")
+			}
 		}
 	}
+	if err := self.validateEnums(); err != nil {
+		return err
+	}
 	//TODO fill this out more?
 	return nil
 }
 
+func (self *Schema) validateEnums() error {
+	enumNames := make(map[string]bool)
+	for _, enum := range self.Enums {
+		if enum.Name == "" {
+			return errors.New("At least one enum missing Name")
+		}
+		if enumNames[strings.ToLower(enum.Name)] {
+			return errors.New("Duplicate enum " + enum.Name)
+		}
+		enumNames[strings.ToLower(enum.Name)] = true
+		if isPortableType(enum.Name) {
+			return errors.New("Enum " + enum.Name + " has the name of a built in type")
+		}
+		for _, model := range self.Models {
+			if strings.EqualFold(model.Name, enum.Name) {
+				return errors.New("Enum " + enum.Name + " has the same name as a model")
+			}
+		}
+		if enum.Type != "" && enum.Type != "string" && enum.Type != "int" && enum.Type != "float" {
+			return errors.New("Enum " + enum.Name + " has unsupported type " + enum.Type + ". Expecting string, int or float")
+		}
+
+		valueNames := make(map[string]bool)
+		rawValues := make(map[string]bool)
+		for _, value := range enum.Values {
+			if value.Name == "" {
+				return errors.New("Enum " + enum.Name + " has at least one value missing it's Name")
+			}
+			if valueNames[value.Name] {
+				return errors.New("Enum " + enum.Name + " has duplicate value " + value.Name)
+			}
+			valueNames[value.Name] = true
+			if value.RawValue == "" {
+				continue
+			}
+			if rawValues[value.RawValue] {
+				return errors.New("Enum " + enum.Name + " has duplicate raw value " + value.RawValue)
+			}
+			rawValues[value.RawValue] = true
+			if enum.Type == "int" {
+				if _, err := strconv.ParseInt(value.RawValue, 10, 64); err != nil {
+					return errors.New("Enum " + enum.Name + " value " + value.Name + " has raw value " + value.RawValue + ", which is not an int")
+				}
+			} else if enum.Type == "float" {
+				if _, err := strconv.ParseFloat(value.RawValue, 64); err != nil {
+					return errors.New("Enum " + enum.Name + " value " + value.Name + " has raw value " + value.RawValue + ", which is not a float")
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (self *Schema) validateRelationship(model Model, property ModelProperty) error {
+	relationship := property.Relationship
+	if relationship.Kind == "" {
+		if relationship != (Relationship{}) {
+			return errors.New("Property " + property.RemoteIdentifier + " of model " + model.Name + " has a relationship without a Kind")
+		}
+		return nil
+	}
+	description := relationship.Kind + " relationship " + property.RemoteIdentifier + " of model " + model.Name
+	switch relationship.Kind {
+	case HasOne, BelongsTo:
+		if property.IsSetType {
+			return errors.New(description + " must not be a set type")
+		}
+	case HasMany:
+		if !property.IsSetType {
+			return errors.New(description + " must be a set type")
+		}
+	default:
+		return errors.New("Property " + property.RemoteIdentifier + " of model " + model.Name + " has unsupported relationship kind " + relationship.Kind + ". Expecting hasOne, hasMany or belongsTo")
+	}
+	switch relationship.OnDelete {
+	case "", DeleteNullify, DeleteCascade, DeleteDeny, DeleteNoAction:
+	default:
+		return errors.New(description + " has unsupported OnDelete rule " + relationship.OnDelete + ". Expecting nullify, cascade, deny or noAction")
+	}
+
+	relatedModel, ok := self.modelNamed(property.PropertyType)
+	if !ok {
+		return errors.New(description + " refers to unknown model " + property.PropertyType)
+	}
+	if relationship.Kind == BelongsTo && len(self.primaryKey(relatedModel)) == 0 {
+		return errors.New(description + " refers to model " + relatedModel.Name + ", which has no PrimaryKey")
+	}
+	if relationship.ForeignKey != "" {
+		if relationship.Kind != BelongsTo {
+			return errors.New(description + " has a ForeignKey. Only belongsTo relationships hold a key")
+		}
+		if _, ok := self.propertyNamed(model, relationship.ForeignKey); !ok {
+			return errors.New(description + " has unknown ForeignKey " + relationship.ForeignKey)
+		}
+	}
+	if relationship.Inverse == "" {
+		return nil
+	}
+
+	inverse, ok := self.propertyNamed(relatedModel, relationship.Inverse)
+	if !ok {
+		return errors.New(description + " has unknown inverse " + relatedModel.Name + "." + relationship.Inverse)
+	}
+	inverseRelationship := inverse.Relationship
+	if inverseRelationship.Kind == "" || !self.isModelOrAncestor(model, inverse.PropertyType) {
+		return errors.New(description + " has inverse " + relatedModel.Name + "." + relationship.Inverse + ", which is not a relationship with " + model.Name)
+	}
+	if inverseRelationship.Inverse != "" && inverseRelationship.Inverse != property.RemoteIdentifier {
+		return errors.New(description + " has inverse " + relatedModel.Name + "." + relationship.Inverse + ", whose own inverse is " + inverseRelationship.Inverse)
+	}
+	//One side of a one to one or one to many relationship holds the key
+	if relationship.Kind != HasMany && (relationship.Kind == BelongsTo) == (inverseRelationship.Kind == BelongsTo) {
+		return errors.New(description + " and its inverse " + relatedModel.Name + "." + relationship.Inverse + " are " + relationship.Kind + " and " + inverseRelationship.Kind + ". Exactly one side must be belongsTo")
+	}
+	if relationship.Kind == HasMany && inverseRelationship.Kind == HasOne {
+		return errors.New(description + " has hasOne inverse " + relatedModel.Name + "." + relationship.Inverse + ". Expecting belongsTo or hasMany")
+	}
+	if relationship.OnDelete == DeleteCascade && inverseRelationship.OnDelete == DeleteCascade {
+		return errors.New(description + " and its inverse " + relatedModel.Name + "." + relationship.Inverse + " both cascade deletes. Only the owning side may")
+	}
+	return nil
+}
+
+func (self *Schema) validateKeys(model Model) error {
+	if err := self.validateKeyProperties(model, model.PrimaryKey, "PrimaryKey of model "+model.Name); err != nil {
+		return err
+	}
+	for _, remoteIdentifier := range model.PrimaryKey {
+		property, _ := self.propertyNamed(model, remoteIdentifier)
+		if property.IsSetType || property.Nullable || property.Relationship.Kind != "" {
+			return errors.New("PrimaryKey of model " + model.Name + " includes " + remoteIdentifier + ". Key properties can't be set types, Nullable or relationships")
+		}
+	}
+
+	indexNames := make(map[string]bool)
+	for _, index := range model.Indexes {
+		description := "Index of model " + model.Name
+		if index.Name != "" {
+			description = "Index " + index.Name + " of model " + model.Name
+			if indexNames[index.Name] {
+				return errors.New("Model " + model.Name + " has duplicate index " + index.Name)
+			}
+			indexNames[index.Name] = true
+		}
+		if len(index.Properties) == 0 {
+			return errors.New(description + " has no Properties")
+		}
+		if err := self.validateKeyProperties(model, index.Properties, description); err != nil {
+			return err
+		}
+	}
+
+	for _, foreignKey := range model.ForeignKeys {
+		description := "Foreign key of model " + model.Name + " referencing " + foreignKey.ReferencedModel
+		if len(foreignKey.Properties) == 0 {
+			return errors.New(description + " has no Properties")
+		}
+		if err := self.validateKeyProperties(model, foreignKey.Properties, description); err != nil {
+			return err
+		}
+		referencedModel, ok := self.modelNamed(foreignKey.ReferencedModel)
+		if !ok {
+			return errors.New(description + " refers to unknown model " + foreignKey.ReferencedModel)
+		}
+		referencedProperties := foreignKey.ReferencedProperties
+		if len(referencedProperties) == 0 {
+			//The key of the referenced model is implied
+			referencedProperties = self.primaryKey(referencedModel)
+			if len(referencedProperties) == 0 {
+				return errors.New(description + " refers to model " + referencedModel.Name + ", which has no PrimaryKey")
+			}
+		} else if err := self.validateKeyProperties(referencedModel, referencedProperties, description); err != nil {
+			return err
+		}
+		if len(referencedProperties) != len(foreignKey.Properties) {
+			return errors.New(description + " has " + strconv.Itoa(len(foreignKey.Properties)) + " properties but references " + strconv.Itoa(len(referencedProperties)))
+		}
+	}
+	return nil
+}
+
+//validateKeyProperties checks that remoteIdentifiers name distinct
+//properties of model.
+func (self *Schema) validateKeyProperties(model Model, remoteIdentifiers []string, description string) error {
+	seen := make(map[string]bool)
+	for _, remoteIdentifier := range remoteIdentifiers {
+		if seen[remoteIdentifier] {
+			return errors.New(description + " lists " + remoteIdentifier + " twice")
+		}
+		seen[remoteIdentifier] = true
+		if _, ok := self.propertyNamed(model, remoteIdentifier); !ok {
+			return errors.New(description + " refers to unknown property " + model.Name + "." + remoteIdentifier)
+		}
+	}
+	return nil
+}
+
+//primaryKey returns the RemoteIdentifiers of the key properties of model,
+//as found by primaryKeyProperties. The result is empty when model has no
+//resolvable key.
+func (self *Schema) primaryKey(model Model) []string {
+	keyProperties := primaryKeyProperties(model, self.parentOf)
+	remoteIdentifiers := make([]string, 0, len(keyProperties))
+	for _, property := range keyProperties {
+		remoteIdentifiers = append(remoteIdentifiers, property.RemoteIdentifier)
+	}
+	return remoteIdentifiers
+}
+
+func (self *Schema) parentOf(model Model) (Model, bool) {
+	return self.modelNamed(model.Parent)
+}
+
+//withParentRefs returns copies of models whose ParentRefs, and those of
+//their parents, point to the schema's models.
+func (self *Schema) withParentRefs(models []Model) []Model {
+	linkedModels := make([]Model, len(self.Models))
+	copy(linkedModels, self.Models)
+	indexes := make(map[string]int)
+	for index, model := range linkedModels {
+		indexes[model.Name] = index
+	}
+	for index := range linkedModels {
+		if parentIndex, ok := indexes[linkedModels[index].Parent]; ok {
+			linkedModels[index].ParentRef = &linkedModels[parentIndex]
+		}
+	}
+	result := make([]Model, len(models))
+	for index, model := range models {
+		result[index] = model
+		if parentIndex, ok := indexes[model.Parent]; ok {
+			result[index].ParentRef = &linkedModels[parentIndex]
+		}
+	}
+	return result
+}
+
+//primaryKeyProperties is the key lookup shared by the schema and the
+//template helpers, which find parents differently. The key is the declared
+//PrimaryKey of model or its nearest ancestor declaring one, and otherwise a
+//property named id. Key properties may be inherited.
+func primaryKeyProperties(model Model, parentOf func(Model) (Model, bool)) []ModelProperty {
+	ancestry := make([]Model, 0)
+	visited := make(map[string]bool)
+	for current, ok := model, true; ok && !visited[current.Name]; current, ok = parentOf(current) {
+		visited[current.Name] = true
+		ancestry = append(ancestry, current)
+	}
+	keyProperties := make([]ModelProperty, 0)
+	for _, current := range ancestry {
+		if len(current.PrimaryKey) == 0 {
+			continue
+		}
+		for _, remoteIdentifier := range current.PrimaryKey {
+			if property, ok := propertyInModels(ancestry, remoteIdentifier); ok {
+				keyProperties = append(keyProperties, property)
+			}
+		}
+		return keyProperties
+	}
+	for _, current := range ancestry {
+		for _, property := range current.Properties {
+			if strings.EqualFold(property.RemoteIdentifier, "id") {
+				return append(keyProperties, property)
+			}
+		}
+	}
+	return keyProperties
+}
+
+func propertyInModels(models []Model, remoteIdentifier string) (ModelProperty, bool) {
+	for _, model := range models {
+		for _, property := range model.Properties {
+			if property.RemoteIdentifier == remoteIdentifier {
+				return property, true
+			}
+		}
+	}
+	return ModelProperty{}, false
+}
+
+func (self *Schema) validateDefault(model Model, property ModelProperty) error {
+	if property.Default == nil {
+		return nil
+	}
+	description := "Property " + property.RemoteIdentifier + " of model " + model.Name
+	if property.IsSetType {
+		return errors.New(description + " is a set type and can't have a Default")
+	}
+	if property.Relationship.Kind != "" {
+		return errors.New(description + " is a relationship and can't have a Default")
+	}
+	defaultValue, err := self.typedDefault(property)
+	if err != nil {
+		return errors.New(description + " has an invalid Default. " + err.Error())
+	}
+	if enum, ok := self.enumForType(property.PropertyType); ok {
+		for _, value := range enum.Values {
+			if value.Name == defaultValue {
+				return nil
+			}
+		}
+		return fmt.Errorf("%v has Default %v, which is not a value of enum %v", description, defaultValue, enum.Name)
+	}
+	return nil
+}
+
+//typedDefault converts the Default of property to the Go type matching its
+//PropertyType. Decoded JSON holds numbers as float64 or json.Number.
+func (self *Schema) typedDefault(property ModelProperty) (interface{}, error) {
+	switch strings.ToLower(property.PropertyType) {
+	case "int", "integer", "short", "long":
+		switch value := property.Default.(type) {
+		case int:
+			return int64(value), nil
+		case int64:
+			return value, nil
+		case float64:
+			if value == math.Trunc(value) && math.Abs(value) < 1<<63 {
+				return int64(value), nil
+			}
+		case json.Number:
+			if integer, err := value.Int64(); err == nil {
+				return integer, nil
+			}
+		}
+		return nil, fmt.Errorf("Expecting an integer. Got %v", property.Default)
+	case "float":
+		switch value := property.Default.(type) {
+		case int:
+			return float64(value), nil
+		case int64:
+			return float64(value), nil
+		case float64:
+			return value, nil
+		case json.Number:
+			if number, err := value.Float64(); err == nil {
+				return number, nil
+			}
+		}
+		return nil, fmt.Errorf("Expecting a number. Got %v", property.Default)
+	case "boolean":
+		if value, ok := property.Default.(bool); ok {
+			return value, nil
+		}
+		return nil, fmt.Errorf("Expecting true or false. Got %v", property.Default)
+	case "char", "character":
+		if value, ok := property.Default.(string); ok && utf8.RuneCountInString(value) == 1 {
+			return value, nil
+		}
+		return nil, fmt.Errorf("Expecting a single character. Got %v", property.Default)
+	}
+	if value, ok := property.Default.(string); ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("Expecting a string. Got %v", property.Default)
+}
+
+func (self *Schema) modelNamed(name string) (Model, bool) {
+	for _, model := range self.Models {
+		if model.Name == name {
+			return model, true
+		}
+	}
+	return Model{}, false
+}
+
+//propertyNamed looks for a property of model, or of one of its parents,
+//by RemoteIdentifier.
+func (self *Schema) propertyNamed(model Model, remoteIdentifier string) (ModelProperty, bool) {
+	visited := make(map[string]bool)
+	for ok := true; ok && !visited[model.Name]; model, ok = self.modelNamed(model.Parent) {
+		visited[model.Name] = true
+		for _, property := range model.Properties {
+			if property.RemoteIdentifier == remoteIdentifier {
+				return property, true
+			}
+		}
+	}
+	return ModelProperty{}, false
+}
+
+//isModelOrAncestor reports whether name is model or one of its parents.
+func (self *Schema) isModelOrAncestor(model Model, name string) bool {
+	visited := make(map[string]bool)
+	for ok := true; ok && !visited[model.Name]; model, ok = self.modelNamed(model.Parent) {
+		visited[model.Name] = true
+		if model.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+//enumForType returns the enum whose name matches propertyType, ignoring
+//case.
+func (self *Schema) enumForType(propertyType string) (Enum, bool) {
+	for _, enum := range self.Enums {
+		if strings.EqualFold(enum.Name, propertyType) {
+			return enum, true
+		}
+	}
+	return Enum{}, false
+}
+
 func appendIfUnique(slice []*TemplateInfo, item *TemplateInfo) []*TemplateInfo {
 	for _, existingTemplate := range slice {
 		if existingTemplate.FileName == item.FileName && existingTemplate.Directory == item.Directory {
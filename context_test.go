@@ -309,3 +309,212 @@ func TestContextModelForName(testing *testing.T) {
 		testing.Errorf("Non-empty model returned for broken model name")
 	}
 }
+
+func TestContextAddEnum(testing *testing.T) {
+	SetupContext()
+
+	enum, err := context.AddEnum(Enum{Name: "Color", Values: []EnumValue{{Name: "Red"}, {Name: "Green"}}})
+	if err != nil {
+		testing.Errorf("Error while adding valid enum: %v", err.Error())
+	} else if enum.Type != "string" {
+		testing.Errorf("Expecting %v. Got %v", "string", enum.Type)
+	}
+	if _, err := context.AddEnum(Enum{Name: "Color"}); err == nil {
+		testing.Errorf("No error returned for duplicate enum")
+	}
+	if _, err := context.AddEnum(Enum{}); err == nil {
+		testing.Errorf("No error returned for enum without a name")
+	}
+
+	if enum, err := context.EnumForName("Color"); err != nil {
+		testing.Errorf("Error returned for valid enum name: %v", err.Error())
+	} else if len(enum.Values) != 2 {
+		testing.Errorf("Expecting %v values. Got %v", 2, len(enum.Values))
+	}
+	if enum, err := context.EnumForName(brokenModelName); err == nil {
+		testing.Errorf("No error returned for broken enum name")
+	} else if reflect.DeepEqual(*enum, Enum{}) == false {
+		testing.Errorf("Non-empty enum returned for broken enum name")
+	}
+}
+
+func TestSchemaValidateEnums(testing *testing.T) {
+	validSchema := Schema{
+		Models: []Model{{Name: "Car", Properties: []ModelProperty{{RemoteIdentifier: "color", PropertyType: "Color"}}}},
+		Enums: []Enum{
+			{Name: "Color", Type: "string", Values: []EnumValue{{Name: "Red"}, {Name: "Green", RawValue: "green"}}},
+			{Name: "Gear", Type: "int", Values: []EnumValue{{Name: "Park", RawValue: "0"}, {Name: "Drive", RawValue: "1"}}},
+		},
+	}
+	if err := validSchema.validate(); err != nil {
+		testing.Errorf("Error while validating valid schema: %v", err.Error())
+	}
+
+	invalidSchemas := map[string]Schema{
+		"misspelt enum reference": {Models: []Model{{Name: "Car", Properties: []ModelProperty{{RemoteIdentifier: "color", PropertyType: "color"}}}}, Enums: []Enum{{Name: "Color"}}},
+		"enum without name":       {Enums: []Enum{{Values: []EnumValue{{Name: "Red"}}}}},
+		"duplicate enum":          {Enums: []Enum{{Name: "Color"}, {Name: "color"}}},
+		"enum named like a model": {Models: []Model{{Name: "Car"}}, Enums: []Enum{{Name: "Car"}}},
+		"enum named like a type":  {Enums: []Enum{{Name: "String"}}},
+		"unsupported enum type":   {Enums: []Enum{{Name: "Color", Type: "boolean"}}},
+		"value without name":      {Enums: []Enum{{Name: "Color", Values: []EnumValue{{RawValue: "red"}}}}},
+		"duplicate value":         {Enums: []Enum{{Name: "Color", Values: []EnumValue{{Name: "Red"}, {Name: "Red"}}}}},
+		"duplicate raw value":     {Enums: []Enum{{Name: "Color", Values: []EnumValue{{Name: "Red", RawValue: "r"}, {Name: "Rouge", RawValue: "r"}}}}},
+		"non numeric int value":   {Enums: []Enum{{Name: "Gear", Type: "int", Values: []EnumValue{{Name: "Park", RawValue: "P"}}}}},
+		"non numeric float value": {Enums: []Enum{{Name: "Ratio", Type: "float", Values: []EnumValue{{Name: "Half", RawValue: "half"}}}}},
+	}
+	for description, schema := range invalidSchemas {
+		if err := schema.validate(); err == nil {
+			testing.Errorf("validate did not fail for %v", description)
+		}
+	}
+}
+
+func relationshipSchema(ownerRelationship Relationship, petRelationship Relationship) Schema {
+	owner := Model{Name: "Person", Properties: []ModelProperty{{RemoteIdentifier: "id", PropertyType: "int"}, {RemoteIdentifier: "pets", PropertyType: "Pet", IsSetType: ownerRelationship.Kind == HasMany, Relationship: ownerRelationship}}}
+	pet := Model{Name: "Pet", Properties: []ModelProperty{{RemoteIdentifier: "ownerId", PropertyType: "int"}, {RemoteIdentifier: "owner", PropertyType: "Person", IsSetType: petRelationship.Kind == HasMany, Relationship: petRelationship}}}
+	return Schema{Models: []Model{owner, pet}}
+}
+
+func TestSchemaValidateRelationships(testing *testing.T) {
+	validSchemas := map[string]Schema{
+		"one to many":          relationshipSchema(Relationship{Kind: HasMany, Inverse: "owner", OnDelete: DeleteCascade}, Relationship{Kind: BelongsTo, Inverse: "pets", ForeignKey: "ownerId"}),
+		"one to one":           relationshipSchema(Relationship{Kind: HasOne, Inverse: "owner"}, Relationship{Kind: BelongsTo}),
+		"many to many":         relationshipSchema(Relationship{Kind: HasMany, Inverse: "owner"}, Relationship{Kind: HasMany, Inverse: "pets"}),
+		"without inverse":      relationshipSchema(Relationship{Kind: HasMany, OnDelete: DeleteDeny}, Relationship{}),
+		"not a relationship":   relationshipSchema(Relationship{}, Relationship{}),
+		"inverse on a subtype": {Models: []Model{{Name: "Person", PrimaryKey: []string{"login"}, Properties: []ModelProperty{{RemoteIdentifier: "login", PropertyType: "string"}, {RemoteIdentifier: "pets", PropertyType: "Pet", IsSetType: true, Relationship: Relationship{Kind: HasMany, Inverse: "owner"}}}}, {Name: "Animal", Properties: []ModelProperty{{RemoteIdentifier: "owner", PropertyType: "Person", Relationship: Relationship{Kind: BelongsTo}}}}, {Name: "Pet", Parent: "Animal"}}},
+	}
+	for description, schema := range validSchemas {
+		if err := schema.validate(); err != nil {
+			testing.Errorf("Error while validating %v: %v", description, err.Error())
+		}
+	}
+
+	invalidSchemas := map[string]Schema{
+		"unknown kind":              relationshipSchema(Relationship{Kind: "manyToMany"}, Relationship{}),
+		"relationship without kind": relationshipSchema(Relationship{Inverse: "owner"}, Relationship{}),
+		"unknown OnDelete":          relationshipSchema(Relationship{Kind: HasMany, OnDelete: "explode"}, Relationship{}),
+		"hasMany not a set":         {Models: []Model{{Name: "Person", Properties: []ModelProperty{{RemoteIdentifier: "pets", PropertyType: "Person", Relationship: Relationship{Kind: HasMany}}}}}},
+		"hasOne set":                {Models: []Model{{Name: "Person", Properties: []ModelProperty{{RemoteIdentifier: "pet", PropertyType: "Person", IsSetType: true, Relationship: Relationship{Kind: HasOne}}}}}},
+		"unknown model":             {Models: []Model{{Name: "Person", Properties: []ModelProperty{{RemoteIdentifier: "pet", PropertyType: "Pet", Relationship: Relationship{Kind: HasOne}}}}}},
+		"unknown inverse":           relationshipSchema(Relationship{Kind: HasMany, Inverse: "keeper"}, Relationship{Kind: BelongsTo}),
+		"inverse not relationship":  relationshipSchema(Relationship{Kind: HasMany, Inverse: "ownerId"}, Relationship{Kind: BelongsTo}),
+		"mismatched inverses":       relationshipSchema(Relationship{Kind: HasMany, Inverse: "owner"}, Relationship{Kind: BelongsTo, Inverse: "id"}),
+		"two hasOne sides":          relationshipSchema(Relationship{Kind: HasOne, Inverse: "owner"}, Relationship{Kind: HasOne}),
+		"two belongsTo sides":       relationshipSchema(Relationship{Kind: BelongsTo, Inverse: "owner"}, Relationship{Kind: BelongsTo}),
+		"hasMany with hasOne":       relationshipSchema(Relationship{Kind: HasMany, Inverse: "owner"}, Relationship{Kind: HasOne}),
+		"both sides cascade":        relationshipSchema(Relationship{Kind: HasMany, Inverse: "owner", OnDelete: DeleteCascade}, Relationship{Kind: BelongsTo, OnDelete: DeleteCascade}),
+		"unknown foreign key":       relationshipSchema(Relationship{Kind: HasMany}, Relationship{Kind: BelongsTo, ForeignKey: "personId"}),
+		"foreign key on hasMany":    relationshipSchema(Relationship{Kind: HasMany, ForeignKey: "id"}, Relationship{}),
+		"belongsTo without key":     {Models: []Model{{Name: "Person"}, {Name: "Pet", Properties: []ModelProperty{{RemoteIdentifier: "owner", PropertyType: "Person", Relationship: Relationship{Kind: BelongsTo}}}}}},
+	}
+	for description, schema := range invalidSchemas {
+		if err := schema.validate(); err == nil {
+			testing.Errorf("validate did not fail for %v", description)
+		}
+	}
+}
+
+func TestSchemaValidateDefaults(testing *testing.T) {
+	defaultSchema := func(property ModelProperty) Schema {
+		property.RemoteIdentifier = "prop"
+		return Schema{Models: []Model{{Name: "Car", Properties: []ModelProperty{property}}}, Enums: []Enum{{Name: "Color", Values: []EnumValue{{Name: "Red"}}}}}
+	}
+	validProperties := []ModelProperty{
+		{PropertyType: "int", Default: int64(3)},
+		{PropertyType: "int", NotNull: true, Default: int64(3)},
+		{PropertyType: "long", Default: float64(4)},
+		{PropertyType: "float", Default: 1.5},
+		{PropertyType: "boolean", Default: true},
+		{PropertyType: "string", Default: "none", Nullable: true},
+		{PropertyType: "char", Default: "x"},
+		{PropertyType: "Color", Default: "Red"},
+	}
+	for _, property := range validProperties {
+		schema := defaultSchema(property)
+		if err := schema.validate(); err != nil {
+			testing.Errorf("Error while validating default %v of %v: %v", property.Default, property.PropertyType, err.Error())
+		}
+	}
+
+	invalidProperties := []ModelProperty{
+		{PropertyType: "int", Default: 1.5},
+		{PropertyType: "int", Default: "3"},
+		{PropertyType: "float", Default: "1.5"},
+		{PropertyType: "boolean", Default: "true"},
+		{PropertyType: "string", Default: int64(3)},
+		{PropertyType: "char", Default: "xy"},
+		{PropertyType: "Color", Default: "Blue"},
+		{PropertyType: "int", IsSetType: true, Default: int64(3)},
+		{PropertyType: "int", Nullable: true, NotNull: true, Default: int64(3)},
+	}
+	for _, property := range invalidProperties {
+		schema := defaultSchema(property)
+		if err := schema.validate(); err == nil {
+			testing.Errorf("validate did not fail for default %v of %v", property.Default, property.PropertyType)
+		}
+	}
+}
+
+func TestSchemaValidateKeys(testing *testing.T) {
+	keySchema := func(person Model) Schema {
+		person.Name = "Person"
+		person.Properties = []ModelProperty{{RemoteIdentifier: "first", PropertyType: "string"}, {RemoteIdentifier: "last", PropertyType: "string"}, {RemoteIdentifier: "nickname", PropertyType: "string", Nullable: true}}
+		pet := Model{Name: "Pet", Properties: []ModelProperty{{RemoteIdentifier: "ownerFirst", PropertyType: "string"}, {RemoteIdentifier: "ownerLast", PropertyType: "string"}}}
+		return Schema{Models: []Model{person, pet, {Name: "Employee", Parent: "Person"}}}
+	}
+	withForeignKey := func(schema Schema, foreignKey ForeignKey) Schema {
+		schema.Models[1].ForeignKeys = []ForeignKey{foreignKey}
+		return schema
+	}
+
+	validSchemas := map[string]Schema{
+		"composite key":          keySchema(Model{PrimaryKey: []string{"first", "last"}}),
+		"unique index":           keySchema(Model{Indexes: []Index{{Name: "names", Properties: []string{"last", "first"}, Unique: true}, {Properties: []string{"nickname"}}}}),
+		"foreign key":            withForeignKey(keySchema(Model{}), ForeignKey{Properties: []string{"ownerFirst"}, ReferencedModel: "Person", ReferencedProperties: []string{"first"}}),
+		"foreign key to the key": withForeignKey(keySchema(Model{PrimaryKey: []string{"first", "last"}}), ForeignKey{Properties: []string{"ownerFirst", "ownerLast"}, ReferencedModel: "Person"}),
+		"inherited key":          withForeignKey(keySchema(Model{PrimaryKey: []string{"first", "last"}}), ForeignKey{Properties: []string{"ownerFirst", "ownerLast"}, ReferencedModel: "Employee"}),
+	}
+	for description, schema := range validSchemas {
+		if err := schema.validate(); err != nil {
+			testing.Errorf("Error while validating %v: %v", description, err.Error())
+		}
+	}
+
+	invalidSchemas := map[string]Schema{
+		"unknown key property":        keySchema(Model{PrimaryKey: []string{"middle"}}),
+		"repeated key property":       keySchema(Model{PrimaryKey: []string{"first", "first"}}),
+		"nullable key property":       keySchema(Model{PrimaryKey: []string{"nickname"}}),
+		"empty index":                 keySchema(Model{Indexes: []Index{{Name: "names"}}}),
+		"unknown index property":      keySchema(Model{Indexes: []Index{{Properties: []string{"middle"}}}}),
+		"duplicate index names":       keySchema(Model{Indexes: []Index{{Name: "names", Properties: []string{"first"}}, {Name: "names", Properties: []string{"last"}}}}),
+		"foreign key to no key":       withForeignKey(keySchema(Model{}), ForeignKey{Properties: []string{"ownerFirst"}, ReferencedModel: "Person"}),
+		"foreign key to no model":     withForeignKey(keySchema(Model{}), ForeignKey{Properties: []string{"ownerFirst"}, ReferencedModel: "Owner", ReferencedProperties: []string{"first"}}),
+		"foreign key size mismatch":   withForeignKey(keySchema(Model{PrimaryKey: []string{"first", "last"}}), ForeignKey{Properties: []string{"ownerFirst"}, ReferencedModel: "Person"}),
+		"unknown referenced property": withForeignKey(keySchema(Model{}), ForeignKey{Properties: []string{"ownerFirst"}, ReferencedModel: "Person", ReferencedProperties: []string{"middle"}}),
+	}
+	for description, schema := range invalidSchemas {
+		if err := schema.validate(); err == nil {
+			testing.Errorf("validate did not fail for %v", description)
+		}
+	}
+}
+
+func TestPrimaryKeysFeature(testing *testing.T) {
+	SetupContext()
+	context.AddTemplate(templateFileName, []byte(templateBody), testTemplaterVersion, "template", &context.GoAdapter)
+	model, _ := context.AddModelWithName(modelName)
+	model.AddProperty("name", "name", "string")
+	context.AddTemplatesForModelsMapping([]string{templateFileName}, []string{modelName})
+	context.AddTemplateFeature(FeaturePrimaryKeys)
+
+	if _, err := ProcessMappings(context); err == nil {
+		testing.Errorf("ProcessMappings did not fail for a model without a primary key")
+	}
+
+	context.Mappings[0].Models[0].PrimaryKey = []string{"name"}
+	if _, err := ProcessMappings(context); err != nil {
+		testing.Errorf("Error while processing a model with a primary key: %v", err.Error())
+	}
+}
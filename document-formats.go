@@ -0,0 +1,63 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+//Configuration and model schema files may be written in JSON, YAML or
+//TOML; the format is chosen by file extension. YAML and TOML documents are
+//converted to JSON and read exactly as the equivalent JSON would be.
+const (
+	FormatJSON string = "json"
+	FormatYAML string = "yaml"
+	FormatTOML string = "toml"
+)
+
+//FormatForPath returns FormatYAML for .yaml and .yml files, FormatTOML for
+//.toml files and FormatJSON for anything else.
+func FormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+	return FormatJSON
+}
+
+//convertToJSON returns the JSON equivalent of a document in format.
+func convertToJSON(format string, document []byte) ([]byte, error) {
+	switch format {
+	case FormatYAML:
+		decoded, err := decodeDocumentYAML(document)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(decoded)
+	case FormatTOML:
+		var decoded map[string]interface{}
+		if _, err := toml.Decode(string(document), &decoded); err != nil {
+			return nil, err
+		}
+		return json.Marshal(decoded)
+	}
+	return document, nil
+}
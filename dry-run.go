@@ -0,0 +1,145 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+type DiffStatus int
+
+const (
+	DiffAdded DiffStatus = iota
+	DiffModified
+	DiffUnchanged
+	DiffOrphaned
+)
+
+func (self DiffStatus) String() string {
+	switch self {
+	case DiffAdded:
+		return "added"
+	case DiffModified:
+		return "modified"
+	case DiffUnchanged:
+		return "unchanged"
+	case DiffOrphaned:
+		return "orphaned"
+	}
+	return "unknown"
+}
+
+type FileDiff struct {
+	//Path is relative to the output directory and uses forward slashes
+	Path   string
+	Status DiffStatus
+	Diff   string
+}
+
+//DiffGeneratedFiles compares the output of ProcessMappings with what is
+//already in outputDirectory without writing anything. Files that exist in
+//the output directory but were not generated are reported as orphaned.
+func DiffGeneratedFiles(outputDirectory string, files []GeneratedFile) ([]FileDiff, error) {
+	writer := GetFileSystemWriter(outputDirectory)
+	filesByPath := make(map[string]GeneratedFile)
+	for _, file := range files {
+		relativePath, err := file.RelativePath()
+		if err != nil {
+			return []FileDiff{}, err
+		}
+		filesByPath[filepath.ToSlash(relativePath)] = file
+	}
+
+	diffs := make([]FileDiff, 0, len(filesByPath))
+	for relativePath, file := range filesByPath {
+		plan, err := writer.planFile(file)
+		if err != nil {
+			return []FileDiff{}, err
+		}
+		fileDiff := FileDiff{Path: relativePath}
+		switch plan.Status {
+		case FileCreated:
+			fileDiff.Status = DiffAdded
+			fileDiff.Diff = UnifiedDiff("/dev/null", "b/"+relativePath, []byte{}, plan.Body)
+		case FileOverwritten:
+			fileDiff.Status = DiffModified
+			if plan.ExistingMode != plan.Mode {
+				fileDiff.Diff = fmt.Sprintf("old mode %o\nnew mode %o\n", plan.ExistingMode, plan.Mode)
+			}
+			fileDiff.Diff += UnifiedDiff("a/"+relativePath, "b/"+relativePath, plan.ExistingBody, plan.Body)
+		case FileUnchanged:
+			fileDiff.Status = DiffUnchanged
+		}
+		diffs = append(diffs, fileDiff)
+	}
+
+	orphans, err := orphanedFiles(outputDirectory, filesByPath)
+	if err != nil {
+		return []FileDiff{}, err
+	}
+	diffs = append(diffs, orphans...)
+
+	sort.Sort(fileDiffsByPath(diffs))
+	return diffs, nil
+}
+
+func orphanedFiles(outputDirectory string, filesByPath map[string]GeneratedFile) ([]FileDiff, error) {
+	orphans := make([]FileDiff, 0)
+	if _, err := os.Stat(outputDirectory); os.IsNotExist(err) {
+		return orphans, nil
+	}
+	err := filepath.Walk(outputDirectory, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" || info.Name() == ".hg" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		relativePath, err := filepath.Rel(outputDirectory, path)
+		if err != nil {
+			return err
+		}
+		relativePath = filepath.ToSlash(relativePath)
+		if relativePath == ManifestFileName {
+			return nil
+		}
+		if _, ok := filesByPath[relativePath]; !ok {
+			orphans = append(orphans, FileDiff{Path: relativePath, Status: DiffOrphaned})
+		}
+		return nil
+	})
+	return orphans, err
+}
+
+func HasChanges(diffs []FileDiff) bool {
+	for _, fileDiff := range diffs {
+		if fileDiff.Status == DiffAdded || fileDiff.Status == DiffModified {
+			return true
+		}
+	}
+	return false
+}
+
+type fileDiffsByPath []FileDiff
+
+func (self fileDiffsByPath) Len() int           { return len(self) }
+func (self fileDiffsByPath) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+func (self fileDiffsByPath) Less(i, j int) bool { return self[i].Path < self[j].Path }
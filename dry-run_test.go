@@ -0,0 +1,93 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUnifiedDiff(testing *testing.T) {
+	from := []byte("one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\nnine\n")
+	to := []byte("one\ntwo\nthree\nfour\nFIVE\nsix\nseven\neight\nnine\nten\n")
+	expected := "--- a/numbers\n+++ b/numbers\n@@ -2,8 +2,9 @@\n two\n three\n four\n-five\n+FIVE\n six\n seven\n eight\n nine\n+ten\n"
+	if diff := UnifiedDiff("a/numbers", "b/numbers", from, to); diff != expected {
+		testing.Errorf("Expecting diff:\n%v\nGot:\n%v", expected, diff)
+	}
+
+	if diff := UnifiedDiff("a/numbers", "b/numbers", from, from); diff != "" {
+		testing.Errorf("Expecting no diff for identical bodies. Got:\n%v", diff)
+	}
+
+	expected = "--- /dev/null\n+++ b/new\n@@ -0,0 +1 @@\n+new\n\\ No newline at end of file\n"
+	if diff := UnifiedDiff("/dev/null", "b/new", []byte{}, []byte("new")); diff != expected {
+		testing.Errorf("Expecting diff:\n%v\nGot:\n%v", expected, diff)
+	}
+
+	expected = "Binary files a/image and b/image differ\n"
+	if diff := UnifiedDiff("a/image", "b/image", []byte{0, 1}, []byte{0, 2}); diff != expected {
+		testing.Errorf("Expecting diff:\n%v\nGot:\n%v", expected, diff)
+	}
+}
+
+func TestDiffGeneratedFiles(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	os.MkdirAll(filepath.Join(outputDirectory, "src"), 0755)
+	ioutil.WriteFile(filepath.Join(outputDirectory, "src", "Modified.java"), []byte("class Modified {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(outputDirectory, "src", "Same.java"), []byte("class Same {}\n"), 0644)
+	ioutil.WriteFile(filepath.Join(outputDirectory, "src", "Orphan.java"), []byte("class Orphan {}\n"), 0644)
+
+	files := []GeneratedFile{
+		GeneratedFile{FileName: "Modified.java", Directory: "src", Body: []byte("class Modified { int x; }\n")},
+		GeneratedFile{FileName: "Same.java", Directory: "src", Body: []byte("class Same {}\n")},
+		GeneratedFile{FileName: "Added.java", Directory: "src", Body: []byte("class Added {}\n")},
+	}
+	diffs, err := DiffGeneratedFiles(outputDirectory, files)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+
+	expectedStatuses := map[string]DiffStatus{
+		"src/Added.java":    DiffAdded,
+		"src/Modified.java": DiffModified,
+		"src/Orphan.java":   DiffOrphaned,
+		"src/Same.java":     DiffUnchanged,
+	}
+	if len(diffs) != len(expectedStatuses) {
+		testing.Fatalf("Expecting %v diffs. Got %v", len(expectedStatuses), len(diffs))
+	}
+	if diffs[0].Path != "src/Added.java" {
+		testing.Errorf("Expecting diffs sorted by path. Got %v first", diffs[0].Path)
+	}
+	for _, fileDiff := range diffs {
+		if fileDiff.Status != expectedStatuses[fileDiff.Path] {
+			testing.Errorf("Expecting %v to be %v. Got %v", fileDiff.Path, expectedStatuses[fileDiff.Path], fileDiff.Status)
+		}
+		if (fileDiff.Status == DiffAdded || fileDiff.Status == DiffModified) && fileDiff.Diff == "" {
+			testing.Errorf("Expecting a diff for %v", fileDiff.Path)
+		}
+	}
+	if !HasChanges(diffs) {
+		testing.Errorf("Expecting changes")
+	}
+
+	//Nothing should have been written
+	if _, err := os.Stat(filepath.Join(outputDirectory, "src", "Added.java")); !os.IsNotExist(err) {
+		testing.Errorf("Dry run wrote a file")
+	}
+}
@@ -0,0 +1,128 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//text/template reports positions as "template: name:line:col: message".
+//The column is only present for execution errors.
+var templateErrorRegex *regexp.Regexp = regexp.MustCompile(`(?s)^template: .*?:(\d+):(?:(\d+):)? (.*)$`)
+
+type GenerationStage int
+
+const (
+	StageParse GenerationStage = iota
+	StageExecute
+)
+
+func (self GenerationStage) String() string {
+	switch self {
+	case StageParse:
+		return "parse"
+	case StageExecute:
+		return "execute"
+	}
+	return "unknown"
+}
+
+//GenerationError describes a template that failed to parse or execute.
+//Line and Column are zero when text/template did not report them. Parse
+//errors are not tied to a mapping and have a MappingIndex of -1. ModelName
+//is only set when the mapping has a single model; ModelNames always lists
+//every model the template was given.
+type GenerationError struct {
+	Stage        GenerationStage
+	TemplatePath string
+	Line         int
+	Column       int
+	MappingIndex int
+	ModelName    string
+	ModelNames   []string
+	Err          error
+}
+
+func (self *GenerationError) Error() string {
+	location := self.TemplatePath
+	if self.Line > 0 {
+		location += ":" + strconv.Itoa(self.Line)
+		if self.Column > 0 {
+			location += ":" + strconv.Itoa(self.Column)
+		}
+	}
+	details := make([]string, 0)
+	if self.MappingIndex >= 0 {
+		details = append(details, "mapping "+strconv.Itoa(self.MappingIndex))
+	}
+	if self.ModelName != "" {
+		details = append(details, "model "+self.ModelName)
+	}
+	message := "Unable to " + self.Stage.String() + " template " + location
+	if len(details) > 0 {
+		message += " (" + strings.Join(details, ", ") + ")"
+	}
+	return message + ": " + self.message()
+}
+
+func (self *GenerationError) Unwrap() error {
+	return self.Err
+}
+
+//message strips the position prefix text/template puts on its errors,
+//since Error already reports it.
+func (self *GenerationError) message() string {
+	if self.Err == nil {
+		return ""
+	}
+	if match := templateErrorRegex.FindStringSubmatch(self.Err.Error()); match != nil {
+		return match[3]
+	}
+	return self.Err.Error()
+}
+
+func newGenerationError(stage GenerationStage, templateInfo TemplateInfo, err error) *GenerationError {
+	generationError := &GenerationError{Stage: stage, TemplatePath: templatePath(&templateInfo), MappingIndex: -1, Err: err}
+	if match := templateErrorRegex.FindStringSubmatch(err.Error()); match != nil {
+		generationError.Line, _ = strconv.Atoi(match[1])
+		generationError.Column, _ = strconv.Atoi(match[2])
+	}
+	return generationError
+}
+
+//annotateGenerationError records which mapping and models a failed task was
+//rendering. Adapters that don't return a GenerationError get one wrapped
+//around their error.
+func annotateGenerationError(err error, task generationTask) error {
+	var generationError *GenerationError
+	if errors.As(err, &generationError) {
+		annotated := *generationError
+		generationError = &annotated
+	} else {
+		generationError = newGenerationError(StageExecute, *task.Template, err)
+	}
+	generationError.MappingIndex = task.MappingIndex
+	generationError.ModelNames = make([]string, 0, len(task.Models))
+	for _, model := range task.Models {
+		generationError.ModelNames = append(generationError.ModelNames, model.Name)
+	}
+	if len(task.Models) == 1 {
+		generationError.ModelName = task.Models[0].Name
+	}
+	return generationError
+}
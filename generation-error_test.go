@@ -0,0 +1,118 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestGenerationErrorForParseFailure(testing *testing.T) {
+	SetupContext()
+	SetupModel()
+	context.AddTemplate("Broken.lt", []byte("<<levo filename:broken.txt>>\n{{.ProjectName}\n<<levo>>\n"), testTemplaterVersion, "templates", &context.GoAdapter)
+	context.AddTemplatesForModelsMapping([]string{"Broken.lt"}, []string{modelName})
+
+	_, err := ProcessMappings(context)
+	var generationError *GenerationError
+	if !errors.As(err, &generationError) {
+		testing.Fatalf("Expecting a GenerationError. Got %v", err)
+	}
+	if generationError.Stage != StageParse {
+		testing.Errorf("Expecting stage %v. Got %v", StageParse, generationError.Stage)
+	}
+	if generationError.TemplatePath != "templates/Broken.lt" {
+		testing.Errorf("Expecting template path %v. Got %v", "templates/Broken.lt", generationError.TemplatePath)
+	}
+	if generationError.Line != 2 {
+		testing.Errorf("Expecting line %v. Got %v", 2, generationError.Line)
+	}
+	if generationError.MappingIndex != -1 {
+		testing.Errorf("Expecting mapping index %v. Got %v", -1, generationError.MappingIndex)
+	}
+}
+
+func TestGenerationErrorForExecutionFailure(testing *testing.T) {
+	SetupContext()
+	SetupTemplate()
+	SetupModel()
+	context.AddModelWithName("TestModel02")
+	context.AddTemplate("Broken.lt", []byte("<<levo filename:broken.txt>>\n{{range .Models}}\n  {{.Name}} {{index .Properties 3}}\n{{end}}\n<<levo>>\n"), testTemplaterVersion, "templates", &context.GoAdapter)
+	SetupMapping()
+	context.AddTemplatesForModelsMapping([]string{"Broken.lt"}, []string{modelName})
+
+	_, err := ProcessMappings(context)
+	var generationError *GenerationError
+	if !errors.As(err, &generationError) {
+		testing.Fatalf("Expecting a GenerationError. Got %v", err)
+	}
+	if generationError.Stage != StageExecute {
+		testing.Errorf("Expecting stage %v. Got %v", StageExecute, generationError.Stage)
+	}
+	if generationError.Line != 3 || generationError.Column == 0 {
+		testing.Errorf("Expecting line %v with a column. Got %v:%v", 3, generationError.Line, generationError.Column)
+	}
+	if generationError.MappingIndex != 1 {
+		testing.Errorf("Expecting mapping index %v. Got %v", 1, generationError.MappingIndex)
+	}
+	if generationError.ModelName != modelName {
+		testing.Errorf("Expecting model %v. Got %v", modelName, generationError.ModelName)
+	}
+	if !reflect.DeepEqual(generationError.ModelNames, []string{modelName}) {
+		testing.Errorf("Expecting models %v. Got %v", []string{modelName}, generationError.ModelNames)
+	}
+	expectedPrefix := "Unable to execute template templates/Broken.lt:3:"
+	if message := generationError.Error(); len(message) < len(expectedPrefix) || message[:len(expectedPrefix)] != expectedPrefix {
+		testing.Errorf("Expecting message starting with %v. Got %v", expectedPrefix, message)
+	}
+}
+
+func TestProcessMappingsCollectErrors(testing *testing.T) {
+	SetupContext()
+	SetupTemplate()
+	SetupModel()
+	context.AddTemplate("Unparsable.lt", []byte("{{.ProjectName}"), testTemplaterVersion, "templates", &context.GoAdapter)
+	context.AddTemplate("Failing.lt", []byte("{{index .Models 5}}"), testTemplaterVersion, "templates", &context.GoAdapter)
+	context.AddTemplatesForModelsMapping([]string{"Unparsable.lt"}, []string{modelName})
+	context.AddTemplatesForModelsMapping([]string{"Failing.lt"}, []string{modelName})
+	SetupMapping()
+
+	//By default the first failure stops generation
+	if generatedFiles, err := ProcessMappings(context); err == nil {
+		testing.Errorf("No error returned for broken templates")
+	} else if len(generatedFiles) != 0 {
+		testing.Errorf("Expecting no files. Got %v", len(generatedFiles))
+	}
+
+	context.CollectErrors = true
+	generatedFiles, err := ProcessMappings(context)
+	if err == nil {
+		testing.Fatalf("No error returned for broken templates")
+	}
+	if len(generatedFiles) != 2 {
+		testing.Errorf("Expecting %v files from the working templates. Got %v", 2, len(generatedFiles))
+	}
+	generationErrors := GenerationErrors(err)
+	if len(generationErrors) != 2 {
+		testing.Fatalf("Expecting %v errors. Got %v", 2, generationErrors)
+	}
+	if generationErrors[0].Stage != StageParse || generationErrors[0].TemplatePath != "templates/Unparsable.lt" {
+		testing.Errorf("Expecting a parse error for Unparsable.lt. Got %v", generationErrors[0])
+	}
+	if generationErrors[1].Stage != StageExecute || generationErrors[1].MappingIndex != 1 {
+		testing.Errorf("Expecting an execution error for mapping 1. Got %v", generationErrors[1])
+	}
+}
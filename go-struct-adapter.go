@@ -0,0 +1,446 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Go's basic types, by name, and their portable types.
+var goBasicTypes map[string]string = map[string]string{
+	"bool":    "boolean",
+	"int":     "int",
+	"int8":    "int",
+	"int16":   "int",
+	"int32":   "int",
+	"uint":    "int",
+	"uint8":   "int",
+	"uint16":  "int",
+	"uint32":  "int",
+	"byte":    "int",
+	"rune":    "int",
+	"int64":   "long",
+	"uint64":  "long",
+	"float32": "float",
+	"float64": "float",
+	"string":  "string",
+}
+
+var timeType reflect.Type = reflect.TypeOf(time.Time{})
+
+//GoStructAdapter builds a Schema from Go struct types, either at runtime
+//from the values passed to Register or statically by parsing Go source
+//with ProcessSchemaFile and ProcessSchemaString. Each struct becomes a
+//model named after its type. Fields are read the way encoding/json reads
+//them: the json tag's name is the RemoteIdentifier, falling back to the
+//field name, which is always the LocalIdentifier. Unexported fields and
+//fields tagged "-" are skipped. Pointer fields are Nullable and fields
+//tagged omitempty Optional, slices and arrays set IsSetType, []byte is
+//"byte" and time.Time is "date". The first embedded struct becomes the
+//Parent.
+type GoStructAdapter struct {
+	Values []interface{}
+}
+
+func (self *GoStructAdapter) Register(values ...interface{}) {
+	self.Values = append(self.Values, values...)
+}
+
+//SchemaForRegisteredValues returns the models for the types of the
+//registered values, which may be structs or pointers to structs, and for
+//the structs their fields use. Project is the name of the first type's
+//package. Fields that have no levo equivalent, such as maps, are listed in
+//the Unsupported field of the Schema.
+func (self *GoStructAdapter) SchemaForRegisteredValues() (Schema, error) {
+	if len(self.Values) == 0 {
+		return Schema{}, errors.New("No values registered")
+	}
+	importer := &goTypeImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, Names: make(map[reflect.Type]string)}
+	for _, value := range self.Values {
+		valueType := reflect.TypeOf(value)
+		for valueType != nil && valueType.Kind() == reflect.Ptr {
+			valueType = valueType.Elem()
+		}
+		if valueType == nil || valueType.Kind() != reflect.Struct || valueType.Name() == "" {
+			return Schema{}, errors.New("Only values of named struct types can be registered")
+		}
+		if importer.Schema.Project == "" {
+			importer.Schema.Project = filepath.Base(valueType.PkgPath())
+		}
+		importer.importStruct(valueType, valueType.Name())
+	}
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+//ProcessSchemaFile parses a Go source file, or every non-test file of the
+//package in a directory, and returns the models for its exported struct
+//types and the structs they use. Project is the package name.
+func (self *GoStructAdapter) ProcessSchemaFile(sourcePath string) (Schema, error) {
+	fileInfo, err := os.Stat(sourcePath)
+	if err != nil {
+		return Schema{}, err
+	}
+	sourcePaths := []string{sourcePath}
+	if fileInfo.IsDir() {
+		sourcePaths, err = filepath.Glob(filepath.Join(sourcePath, "*.go"))
+		if err != nil {
+			return Schema{}, err
+		}
+		sort.Strings(sourcePaths)
+	}
+
+	fileSet := token.NewFileSet()
+	files := make([]*ast.File, 0, len(sourcePaths))
+	for _, path := range sourcePaths {
+		if strings.HasSuffix(path, "_test.go") {
+			continue
+		}
+		source, err := ioutil.ReadFile(path)
+		if err != nil {
+			return Schema{}, err
+		}
+		file, err := parser.ParseFile(fileSet, path, source, 0)
+		if err != nil {
+			return Schema{}, err
+		}
+		files = append(files, file)
+	}
+	return importGoFiles(files)
+}
+
+func (self *GoStructAdapter) ProcessSchemaString(source string) (Schema, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, 0)
+	if err != nil {
+		return Schema{}, err
+	}
+	return importGoFiles([]*ast.File{file})
+}
+
+//goTypeImporter converts struct types found by reflection.
+type goTypeImporter struct {
+	Schema Schema
+	Names  map[reflect.Type]string
+}
+
+func (self *goTypeImporter) importStruct(structType reflect.Type, name string) string {
+	if existingName, ok := self.Names[structType]; ok {
+		return existingName
+	}
+	self.Names[structType] = name
+	//Reserve the model's place so that it comes before the structs its
+	//fields use.
+	index := len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, Model{Name: name})
+	model := Model{Name: name, Properties: make([]ModelProperty, 0, structType.NumField())}
+
+	for fieldIndex := 0; fieldIndex < structType.NumField(); fieldIndex++ {
+		field := structType.Field(fieldIndex)
+		remoteIdentifier, omitEmpty, skip := jsonFieldName(field.Tag, field.Name)
+		if skip || (field.PkgPath != "" && !field.Anonymous) {
+			continue
+		}
+		path := name + "." + field.Name
+		fieldType := field.Type
+		if field.Anonymous && remoteIdentifier == field.Name {
+			for fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				if model.Parent != "" {
+					self.unsupported(path, "embedded", "only the first embedded struct becomes the parent")
+					continue
+				}
+				model.Parent = self.importStruct(fieldType, fieldType.Name())
+				continue
+			}
+			if field.PkgPath != "" {
+				continue
+			}
+		}
+		propertyType, isSetType, nullable, ok := self.resolveType(path, field.Type, name+field.Name)
+		if !ok {
+			continue
+		}
+		model.Properties = append(model.Properties, ModelProperty{RemoteIdentifier: remoteIdentifier, LocalIdentifier: field.Name, PropertyType: propertyType, IsSetType: isSetType, Optional: omitEmpty, Nullable: nullable})
+	}
+
+	self.Schema.Models[index] = model
+	return name
+}
+
+func (self *goTypeImporter) resolveType(path string, fieldType reflect.Type, inlineName string) (string, bool, bool, bool) {
+	nullable := false
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+		nullable = true
+	}
+	if fieldType == timeType {
+		return "date", false, nullable, true
+	}
+	switch fieldType.Kind() {
+	case reflect.Slice, reflect.Array:
+		if fieldType.Elem().Kind() == reflect.Uint8 {
+			return "byte", false, nullable, true
+		}
+		elementType := fieldType.Elem()
+		for elementType.Kind() == reflect.Ptr {
+			elementType = elementType.Elem()
+		}
+		if (elementType.Kind() == reflect.Slice || elementType.Kind() == reflect.Array) && elementType.Elem().Kind() != reflect.Uint8 {
+			self.unsupported(path, "type", "nested slices")
+			return "", false, false, false
+		}
+		propertyType, _, _, ok := self.resolveType(path, elementType, inlineName)
+		return propertyType, true, nullable, ok
+	case reflect.Struct:
+		name := fieldType.Name()
+		if name == "" {
+			name = inlineName
+		}
+		return self.importStruct(fieldType, name), false, nullable, true
+	}
+	if portableType, ok := goBasicTypes[fieldType.Kind().String()]; ok {
+		return portableType, false, nullable, true
+	}
+	self.unsupported(path, "type", "fields of kind "+fieldType.Kind().String())
+	return "", false, false, false
+}
+
+func (self *goTypeImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+//goSourceImporter converts struct types declared in parsed Go source.
+type goSourceImporter struct {
+	Schema    Schema
+	TypeSpecs map[string]*ast.TypeSpec
+	Imported  map[string]bool
+	resolving map[string]bool
+}
+
+func importGoFiles(files []*ast.File) (Schema, error) {
+	importer := &goSourceImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, TypeSpecs: make(map[string]*ast.TypeSpec), Imported: make(map[string]bool), resolving: make(map[string]bool)}
+	typeSpecs := make([]*ast.TypeSpec, 0)
+	for _, file := range files {
+		if importer.Schema.Project == "" {
+			importer.Schema.Project = file.Name.Name
+		}
+		for _, declaration := range file.Decls {
+			genericDeclaration, ok := declaration.(*ast.GenDecl)
+			if !ok || genericDeclaration.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genericDeclaration.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				importer.TypeSpecs[typeSpec.Name.Name] = typeSpec
+				typeSpecs = append(typeSpecs, typeSpec)
+			}
+		}
+	}
+	for _, typeSpec := range typeSpecs {
+		if structType, ok := typeSpec.Type.(*ast.StructType); ok && ast.IsExported(typeSpec.Name.Name) {
+			importer.importStruct(typeSpec.Name.Name, structType)
+		}
+	}
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+func (self *goSourceImporter) importStruct(name string, structType *ast.StructType) string {
+	if self.Imported[name] {
+		return name
+	}
+	self.Imported[name] = true
+	//Reserve the model's place so that it comes before the structs its
+	//fields use.
+	index := len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, Model{Name: name})
+	model := Model{Name: name, Properties: make([]ModelProperty, 0)}
+
+	for _, field := range structType.Fields.List {
+		tag := reflect.StructTag("")
+		if field.Tag != nil {
+			if unquotedTag, err := strconv.Unquote(field.Tag.Value); err == nil {
+				tag = reflect.StructTag(unquotedTag)
+			}
+		}
+		fieldNames := make([]string, 0, len(field.Names))
+		for _, fieldName := range field.Names {
+			fieldNames = append(fieldNames, fieldName.Name)
+		}
+		embedded := len(fieldNames) == 0
+		if embedded {
+			fieldNames = append(fieldNames, embeddedTypeName(field.Type))
+		}
+
+		for _, fieldName := range fieldNames {
+			remoteIdentifier, omitEmpty, skip := jsonFieldName(tag, fieldName)
+			if skip || (!embedded && !ast.IsExported(fieldName)) {
+				continue
+			}
+			path := name + "." + fieldName
+			if embedded && remoteIdentifier == fieldName {
+				if embeddedSpec, ok := self.TypeSpecs[fieldName]; ok {
+					if embeddedStruct, ok := embeddedSpec.Type.(*ast.StructType); ok {
+						if model.Parent != "" {
+							self.unsupported(path, "embedded", "only the first embedded struct becomes the parent")
+							continue
+						}
+						model.Parent = self.importStruct(fieldName, embeddedStruct)
+						continue
+					}
+				}
+				if !ast.IsExported(fieldName) {
+					continue
+				}
+			}
+			propertyType, isSetType, nullable, ok := self.resolveType(path, field.Type, name+fieldName)
+			if !ok {
+				continue
+			}
+			model.Properties = append(model.Properties, ModelProperty{RemoteIdentifier: remoteIdentifier, LocalIdentifier: fieldName, PropertyType: propertyType, IsSetType: isSetType, Optional: omitEmpty, Nullable: nullable})
+		}
+	}
+
+	self.Schema.Models[index] = model
+	return name
+}
+
+func (self *goSourceImporter) resolveType(path string, expression ast.Expr, inlineName string) (string, bool, bool, bool) {
+	switch typedExpression := expression.(type) {
+	case *ast.ParenExpr:
+		return self.resolveType(path, typedExpression.X, inlineName)
+	case *ast.StarExpr:
+		propertyType, isSetType, _, ok := self.resolveType(path, typedExpression.X, inlineName)
+		return propertyType, isSetType, true, ok
+	case *ast.ArrayType:
+		if isGoByteType(typedExpression.Elt) {
+			return "byte", false, false, true
+		}
+		propertyType, isSetType, _, ok := self.resolveType(path, typedExpression.Elt, inlineName)
+		if ok && isSetType {
+			self.unsupported(path, "type", "nested slices")
+			return "", false, false, false
+		}
+		return propertyType, true, false, ok
+	case *ast.StructType:
+		return self.importStruct(inlineName, typedExpression), false, false, true
+	case *ast.SelectorExpr:
+		packageName, _ := typedExpression.X.(*ast.Ident)
+		if packageName != nil && packageName.Name == "time" && typedExpression.Sel.Name == "Time" {
+			return "date", false, false, true
+		}
+		if packageName != nil && packageName.Name == "time" && typedExpression.Sel.Name == "Duration" {
+			return "long", false, false, true
+		}
+		self.unsupported(path, "type", "types from other packages")
+		return "", false, false, false
+	case *ast.Ident:
+		typeName := typedExpression.Name
+		if typeSpec, ok := self.TypeSpecs[typeName]; ok {
+			if structType, ok := typeSpec.Type.(*ast.StructType); ok {
+				return self.importStruct(typeName, structType), false, false, true
+			}
+			//A named type, e.g. type Status string, has its underlying type
+			if self.resolving[typeName] {
+				self.unsupported(path, "type", "recursive type "+typeName)
+				return "", false, false, false
+			}
+			self.resolving[typeName] = true
+			defer delete(self.resolving, typeName)
+			return self.resolveType(path, typeSpec.Type, typeName)
+		}
+		if portableType, ok := goBasicTypes[typeName]; ok {
+			return portableType, false, false, true
+		}
+	}
+	self.unsupported(path, "type", "fields of type "+goExpressionString(expression))
+	return "", false, false, false
+}
+
+func (self *goSourceImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+//jsonFieldName reads a field's json tag the way encoding/json does.
+func jsonFieldName(tag reflect.StructTag, fieldName string) (string, bool, bool) {
+	jsonTag := tag.Get("json")
+	if jsonTag == "-" {
+		return "", false, true
+	}
+	options := strings.Split(jsonTag, ",")
+	name := options[0]
+	if name == "" {
+		name = fieldName
+	}
+	omitEmpty := false
+	for _, option := range options[1:] {
+		if option == "omitempty" {
+			omitEmpty = true
+		}
+	}
+	return name, omitEmpty, false
+}
+
+func embeddedTypeName(expression ast.Expr) string {
+	switch typedExpression := expression.(type) {
+	case *ast.StarExpr:
+		return embeddedTypeName(typedExpression.X)
+	case *ast.SelectorExpr:
+		return typedExpression.Sel.Name
+	case *ast.Ident:
+		return typedExpression.Name
+	}
+	return ""
+}
+
+func isGoByteType(expression ast.Expr) bool {
+	identifier, ok := expression.(*ast.Ident)
+	return ok && (identifier.Name == "byte" || identifier.Name == "uint8")
+}
+
+func goExpressionString(expression ast.Expr) string {
+	switch typedExpression := expression.(type) {
+	case *ast.Ident:
+		return typedExpression.Name
+	case *ast.SelectorExpr:
+		return goExpressionString(typedExpression.X) + "." + typedExpression.Sel.Name
+	case *ast.MapType:
+		return "map[" + goExpressionString(typedExpression.Key) + "]" + goExpressionString(typedExpression.Value)
+	case *ast.InterfaceType:
+		return "interface{}"
+	case *ast.ChanType:
+		return "chan " + goExpressionString(typedExpression.Value)
+	case *ast.FuncType:
+		return "func"
+	}
+	return reflect.TypeOf(expression).Elem().Name()
+}
@@ -0,0 +1,158 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testStructBase struct {
+	ID      int64     `json:"id"`
+	Created time.Time `json:"created_at"`
+}
+
+type TestStructAuthor struct {
+	testStructBase
+	Name     string            `json:"name"`
+	Nickname *string           `json:"nickname"`
+	Books    []TestStructBook  `json:"books,omitempty"`
+	Avatar   []byte            `json:"avatar"`
+	Extra    map[string]string `json:"extra"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+type TestStructBook struct {
+	Title string
+	Pages uint16 `json:"pages"`
+	Price struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	} `json:"price"`
+}
+
+const testStructSource string = `package library
+
+import "time"
+
+type Status string
+
+type Base struct {
+	ID int64 ` + "`json:\"id\"`" + `
+}
+
+type Author struct {
+	Base
+	Name   string   ` + "`json:\"name\"`" + `
+	Status *Status  ` + "`json:\"status\"`" + `
+	Born   time.Time
+	Books  []*Book  ` + "`json:\"books,omitempty\"`" + `
+	Scores [][]int
+	hidden string
+}
+
+type Book struct {
+	Title string ` + "`json:\"title\"`" + `
+}
+`
+
+func TestSchemaForRegisteredValues(testing *testing.T) {
+	adapter := GetGoStructAdapter()
+	adapter.Register(&TestStructAuthor{})
+	schema, err := adapter.SchemaForRegisteredValues()
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "TestStructAuthor.Extra" {
+		testing.Errorf("Expecting the map field TestStructAuthor.Extra to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if schema.Project == "" {
+		testing.Errorf("Expecting the project to be named after the package")
+	}
+
+	expectedModels := []Model{
+		{Name: "TestStructAuthor", Parent: "testStructBase", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "Name", PropertyType: "string"},
+			{RemoteIdentifier: "nickname", LocalIdentifier: "Nickname", PropertyType: "string", Nullable: true},
+			{RemoteIdentifier: "books", LocalIdentifier: "Books", PropertyType: "TestStructBook", IsSetType: true, Optional: true},
+			{RemoteIdentifier: "avatar", LocalIdentifier: "Avatar", PropertyType: "byte"},
+		}},
+		{Name: "testStructBase", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "ID", PropertyType: "long"},
+			{RemoteIdentifier: "created_at", LocalIdentifier: "Created", PropertyType: "date"},
+		}},
+		{Name: "TestStructBook", Properties: []ModelProperty{
+			{RemoteIdentifier: "Title", LocalIdentifier: "Title", PropertyType: "string"},
+			{RemoteIdentifier: "pages", LocalIdentifier: "Pages", PropertyType: "int"},
+			{RemoteIdentifier: "price", LocalIdentifier: "Price", PropertyType: "TestStructBookPrice"},
+		}},
+		{Name: "TestStructBookPrice", Properties: []ModelProperty{
+			{RemoteIdentifier: "amount", LocalIdentifier: "Amount", PropertyType: "float"},
+			{RemoteIdentifier: "currency", LocalIdentifier: "Currency", PropertyType: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	adapter = GetGoStructAdapter()
+	adapter.Register("not a struct")
+	if _, err := adapter.SchemaForRegisteredValues(); err == nil {
+		testing.Errorf("Registering a string did not fail")
+	}
+}
+
+func TestProcessGoSource(testing *testing.T) {
+	directory, err := ioutil.TempDir("", "levo-go-structs")
+	if err != nil {
+		testing.Fatalf("Unable to create temporary directory: %v", err.Error())
+	}
+	defer os.RemoveAll(directory)
+	ioutil.WriteFile(filepath.Join(directory, "library.go"), []byte(testStructSource), 0644)
+	ioutil.WriteFile(filepath.Join(directory, "library_test.go"), []byte("package library\n\ntype TestOnly struct{}\n"), 0644)
+
+	adapter := GetGoStructAdapter()
+	schema, err := adapter.ProcessSchemaFile(directory)
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "Author.Scores" {
+		testing.Errorf("Expecting the nested slice Author.Scores to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if schema.Project != "library" {
+		testing.Errorf("Expecting project %v. Got %v", "library", schema.Project)
+	}
+
+	expectedModels := []Model{
+		{Name: "Base", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "ID", PropertyType: "long"},
+		}},
+		{Name: "Author", Parent: "Base", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "Name", PropertyType: "string"},
+			{RemoteIdentifier: "status", LocalIdentifier: "Status", PropertyType: "string", Nullable: true},
+			{RemoteIdentifier: "Born", LocalIdentifier: "Born", PropertyType: "date"},
+			{RemoteIdentifier: "books", LocalIdentifier: "Books", PropertyType: "Book", IsSetType: true, Optional: true},
+		}},
+		{Name: "Book", Properties: []ModelProperty{
+			{RemoteIdentifier: "title", LocalIdentifier: "Title", PropertyType: "string"},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	if _, err := adapter.ProcessSchemaString("package broken\ntype {"); err == nil {
+		testing.Errorf("Parsing invalid Go source did not fail")
+	}
+}
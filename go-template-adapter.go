@@ -16,6 +16,7 @@ package levo
 
 import (
 	"bytes"
+	gocontext "context"
 	"errors"
 	"fmt"
 	"regexp"
@@ -24,20 +25,25 @@ import (
 
 type GoTemplateAdapter struct {
 	ParsedTemplates *template.Template
+	TypeRegistry    *TypeRegistry
 }
 
 func (self *GoTemplateAdapter) ParseTemplate(templateInfo TemplateInfo) error {
 	if templateInfo.Version != LibraryVersion {
-		return errors.New("Expecting templates with version " + LibraryVersion + ". Template " + templateInfo.FileName + " has version " + templateInfo.Version)
+		return newGenerationError(StageParse, templateInfo, errors.New("Expecting templates with version "+LibraryVersion+". Template "+templateInfo.FileName+" has version "+templateInfo.Version))
+	}
+	if self.TypeRegistry == nil {
+		self.TypeRegistry = GetTypeRegistry()
 	}
 	newTemplate := template.New(templateInfo.FileName)
 	addCommonUtilitiesToTemplate(newTemplate)
+	addCustomTypeUtilitiesToTemplate(newTemplate, self.TypeRegistry)
 	addJavaUtilitiesToTemplate(newTemplate)
 	addObjectiveCUtilitiesToTempalte(newTemplate)
 	addRailsUitilitiesToTemplate(newTemplate)
 
 	if _, err := newTemplate.Parse(string(templateInfo.Body)); err != nil {
-		return err
+		return newGenerationError(StageParse, templateInfo, err)
 	}
 
 	if self.ParsedTemplates == nil {
@@ -45,26 +51,51 @@ func (self *GoTemplateAdapter) ParseTemplate(templateInfo TemplateInfo) error {
 		self.ParsedTemplates = newTemplate
 	} else {
 		if _, err := self.ParsedTemplates.AddParseTree(templateInfo.FileName, newTemplate.Tree); err != nil {
-			return err
+			return newGenerationError(StageParse, templateInfo, err)
 		}
 	}
 	return nil
 }
 
 func (self *GoTemplateAdapter) GenerateFiles(templateInfo TemplateInfo, templateData TemplateData) ([]GeneratedFile, error) {
+	return self.generateFilesContext(gocontext.Background(), templateInfo, templateData)
+}
+
+//generateFilesContext is unexported so that types embedding
+//GoTemplateAdapter to override GenerateFiles keep being called.
+func (self *GoTemplateAdapter) generateFilesContext(ctx gocontext.Context, templateInfo TemplateInfo, templateData TemplateData) ([]GeneratedFile, error) {
 	fmt.Printf("")
 	err := self.cleanTemplateData(&templateData)
 	if err != nil {
 		return []GeneratedFile{}, err
 	}
 
-	buffer := bytes.NewBufferString("")
-	err = self.ParsedTemplates.ExecuteTemplate(buffer, templateInfo.FileName, templateData)
-	if err != nil {
-		return []GeneratedFile{}, err
-	}
+	//text/template can't be interrupted, but it stops as soon as a write
+	//fails. Templates that loop without writing anything are abandoned by
+	//generateFilesUntilDone instead.
+	return generateFilesUntilDone(ctx, func(ctx gocontext.Context) ([]GeneratedFile, error) {
+		buffer := &contextBuffer{ctx: ctx}
+		err := self.ParsedTemplates.ExecuteTemplate(buffer, templateInfo.FileName, templateData)
+		if err != nil {
+			if ctx.Err() != nil {
+				return []GeneratedFile{}, cancellationError(ctx)
+			}
+			return []GeneratedFile{}, newGenerationError(StageExecute, templateInfo, err)
+		}
+		return self.GetFilesFromOutput(&buffer.Buffer, templateInfo.Directory)
+	})
+}
 
-	return self.GetFilesFromOutput(buffer, templateInfo.Directory)
+type contextBuffer struct {
+	bytes.Buffer
+	ctx gocontext.Context
+}
+
+func (self *contextBuffer) Write(data []byte) (int, error) {
+	if err := self.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return self.Buffer.Write(data)
 }
 
 func (self *GoTemplateAdapter) cleanTemplateData(data *TemplateData) error {
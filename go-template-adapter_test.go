@@ -103,3 +103,24 @@ func TestGetFilesFromOutput(testing *testing.T) {
 		testing.Errorf("Expecting directory %v. Got %v.", "subdir", files[1].Directory)
 	}
 }
+
+func TestGenerateFilesWithEnums(testing *testing.T) {
+	SetupContext()
+	enumTemplateBody := "<<levo filename:{{range .Models}}{{.Name}}{{end}}.java>>\n{{range .Models}}{{range .Properties}}{{if isEnumType . $.Enums}}{{$enum := enumForType . $.Enums}}enum {{$enum.Name}} { {{range $enum.Values}}{{toJavaEnumConstant .}}({{toJavaEnumRawValue $enum .}}) {{end}}}{{end}}{{end}}{{end}}\n<<levo>>\n"
+	context.AddTemplate(templateFileName, []byte(enumTemplateBody), testTemplaterVersion, "template", &context.GoAdapter)
+	context.AddEnum(Enum{Name: "Gear", Type: "int", Values: []EnumValue{{Name: "Park"}, {Name: "Drive", RawValue: "5"}}})
+	model, _ := context.AddModelWithName(modelName)
+	model.AddProperty("gear", "gear", "Gear")
+	context.AddTemplatesForModelsMapping([]string{templateFileName}, []string{modelName})
+
+	generatedFiles, err := ProcessMappings(context)
+	if err != nil {
+		testing.Fatal(err)
+	}
+	expectedBody := "enum Gear { PARK(0) DRIVE(5) }"
+	if len(generatedFiles) != 1 {
+		testing.Errorf("Was expecting %v generated files. Got %v", 1, len(generatedFiles))
+	} else if string(generatedFiles[0].Body) != expectedBody {
+		testing.Errorf("Expecting %v. Got %v", expectedBody, string(generatedFiles[0].Body))
+	}
+}
@@ -0,0 +1,194 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+
+	"github.com/vektah/gqlparser/ast"
+	"github.com/vektah/gqlparser/parser"
+)
+
+var graphQLScalarTypes map[string]string = map[string]string{
+	"Int":     "int",
+	"Float":   "float",
+	"String":  "string",
+	"Boolean": "boolean",
+	"ID":      "string",
+}
+
+//GraphQLAdapter imports a GraphQL schema (SDL). Object, input and
+//interface types become models; the query, mutation and subscription types
+//are skipped. A type's first interface becomes its Parent, and the fields
+//it declares are left to the parent. Lists set IsSetType, fields without a
+//non-null marker are Nullable and enums become Enums. Lists of nullable
+//elements, such as [Post], are imported as lists of non-null elements and
+//reported as unsupported. Custom scalars are
+//kept as property types of the same name, which templates can map with
+//custom types.
+type GraphQLAdapter struct{}
+
+func (self *GraphQLAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	fileContents, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.parseGraphQLSchema(&ast.Source{Name: schemaPath, Input: string(fileContents)})
+}
+
+func (self *GraphQLAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	return self.parseGraphQLSchema(&ast.Source{Input: schemaString})
+}
+
+//parseGraphQLSchema lists constructs that have no levo equivalent, such as
+//unions, in the Unsupported field of the Schema built from the rest of the
+//document.
+func (self *GraphQLAdapter) parseGraphQLSchema(source *ast.Source) (Schema, error) {
+	document, parseErr := parser.ParseSchema(source)
+	if parseErr != nil {
+		return Schema{}, parseErr
+	}
+
+	importer := &graphQLImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, Definitions: make(map[string]*ast.Definition)}
+	importer.findRootTypes(document)
+	definitions := make([]*ast.Definition, 0, len(document.Definitions))
+	for _, definition := range document.Definitions {
+		//Copied, so that extensions don't modify the parsed document
+		merged := *definition
+		definitions = append(definitions, &merged)
+		importer.Definitions[definition.Name] = &merged
+	}
+	for _, extension := range document.Extensions {
+		importer.extend(extension)
+	}
+	for _, definition := range definitions {
+		importer.importDefinition(definition)
+	}
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+type graphQLImporter struct {
+	Schema      Schema
+	Definitions map[string]*ast.Definition
+	RootTypes   map[string]bool
+}
+
+func (self *graphQLImporter) findRootTypes(document *ast.SchemaDocument) {
+	self.RootTypes = make(map[string]bool)
+	schemaDefinitions := append(append(ast.SchemaDefinitionList{}, document.Schema...), document.SchemaExtension...)
+	for _, schemaDefinition := range schemaDefinitions {
+		for _, operationType := range schemaDefinition.OperationTypes {
+			self.RootTypes[operationType.Type] = true
+		}
+	}
+	if len(self.RootTypes) == 0 {
+		self.RootTypes = map[string]bool{"Query": true, "Mutation": true, "Subscription": true}
+	}
+}
+
+func (self *graphQLImporter) extend(extension *ast.Definition) {
+	definition, ok := self.Definitions[extension.Name]
+	if !ok || definition.Kind != extension.Kind {
+		self.unsupported(extension.Name, "extend", "extensions of types defined elsewhere")
+		return
+	}
+	definition.Interfaces = append(append([]string{}, definition.Interfaces...), extension.Interfaces...)
+	definition.Fields = append(append(ast.FieldList{}, definition.Fields...), extension.Fields...)
+	definition.EnumValues = append(append(ast.EnumValueList{}, definition.EnumValues...), extension.EnumValues...)
+}
+
+func (self *graphQLImporter) importDefinition(definition *ast.Definition) {
+	switch definition.Kind {
+	case ast.Object, ast.InputObject, ast.Interface:
+		if definition.Kind == ast.Object && self.RootTypes[definition.Name] {
+			return
+		}
+		self.importModel(definition)
+	case ast.Enum:
+		enum := Enum{Name: definition.Name, Type: "string", Values: make([]EnumValue, 0, len(definition.EnumValues))}
+		for _, value := range definition.EnumValues {
+			enum.Values = append(enum.Values, EnumValue{Name: value.Name, RawValue: value.Name})
+		}
+		self.Schema.Enums = append(self.Schema.Enums, enum)
+	case ast.Union:
+		self.unsupported(definition.Name, "union", "models can't be unions")
+	}
+	//Custom scalars need no model; properties keep their name as the type
+}
+
+func (self *graphQLImporter) importModel(definition *ast.Definition) {
+	model := Model{Name: definition.Name, Properties: make([]ModelProperty, 0, len(definition.Fields))}
+	inherited := make(map[string]bool)
+	for index, interfaceName := range definition.Interfaces {
+		if index > 0 {
+			self.unsupported(definition.Name, "implements", "only the first interface, "+model.Parent+", becomes the parent; "+interfaceName+" is ignored")
+			continue
+		}
+		model.Parent = interfaceName
+		if parent, ok := self.Definitions[interfaceName]; ok {
+			for _, field := range parent.Fields {
+				inherited[field.Name] = true
+			}
+		}
+	}
+	for _, field := range definition.Fields {
+		if inherited[field.Name] {
+			continue
+		}
+		if property, ok := self.property(definition.Name+"."+field.Name, field); ok {
+			model.Properties = append(model.Properties, property)
+		}
+	}
+	self.Schema.Models = append(self.Schema.Models, model)
+}
+
+func (self *graphQLImporter) property(path string, field *ast.FieldDefinition) (ModelProperty, bool) {
+	property := ModelProperty{RemoteIdentifier: field.Name, LocalIdentifier: field.Name, Nullable: !field.Type.NonNull}
+	fieldType := field.Type
+	if fieldType.Elem != nil {
+		property.IsSetType = true
+		fieldType = fieldType.Elem
+		if fieldType.Elem != nil {
+			self.unsupported(path, "type", "nested lists")
+			return property, false
+		}
+		if !fieldType.NonNull {
+			//Nullable only covers the property itself, so the list is kept with non-null elements
+			self.unsupported(path, "type", "list elements can't be null; the elements of "+field.Name+" are imported as non-null")
+		}
+	}
+	if scalarType, ok := graphQLScalarTypes[fieldType.NamedType]; ok {
+		property.PropertyType = scalarType
+		return property, true
+	}
+	definition, ok := self.Definitions[fieldType.NamedType]
+	if !ok {
+		self.unsupported(path, "type", "type "+fieldType.NamedType+" is not defined in this schema")
+		return property, false
+	}
+	if definition.Kind == ast.Union {
+		self.unsupported(path, "type", "properties can't be unions")
+		return property, false
+	}
+	property.PropertyType = definition.Name
+	return property, true
+}
+
+func (self *graphQLImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
@@ -0,0 +1,87 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessGraphQLFile(testing *testing.T) {
+	adapter := GetGraphQLAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/schema.graphql")
+	if err != nil || len(schema.Unsupported) != 2 || schema.Unsupported[0].Path != "User.posts" || schema.Unsupported[1].Keyword != "union" {
+		testing.Errorf("Expecting the nullable elements of User.posts and the SearchResult union to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+
+	expectedModels := []Model{
+		{Name: "Node", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "string"},
+		}},
+		{Name: "User", Parent: "Node", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"},
+			{RemoteIdentifier: "email", LocalIdentifier: "email", PropertyType: "string", Nullable: true},
+			{RemoteIdentifier: "roles", LocalIdentifier: "roles", PropertyType: "Role", IsSetType: true},
+			{RemoteIdentifier: "joined", LocalIdentifier: "joined", PropertyType: "DateTime", Nullable: true},
+			{RemoteIdentifier: "posts", LocalIdentifier: "posts", PropertyType: "Post", IsSetType: true, Nullable: true},
+		}},
+		{Name: "Post", Parent: "Node", Properties: []ModelProperty{
+			{RemoteIdentifier: "title", LocalIdentifier: "title", PropertyType: "string"},
+			{RemoteIdentifier: "score", LocalIdentifier: "score", PropertyType: "float", Nullable: true},
+			{RemoteIdentifier: "published", LocalIdentifier: "published", PropertyType: "boolean"},
+		}},
+		{Name: "NewPost", Properties: []ModelProperty{
+			{RemoteIdentifier: "title", LocalIdentifier: "title", PropertyType: "string"},
+			{RemoteIdentifier: "tags", LocalIdentifier: "tags", PropertyType: "string", IsSetType: true, Nullable: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	expectedEnums := []Enum{{Name: "Role", Type: "string", Values: []EnumValue{{Name: "ADMIN", RawValue: "ADMIN"}, {Name: "MEMBER", RawValue: "MEMBER"}}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting enums %v. Got %v", expectedEnums, schema.Enums)
+	}
+}
+
+func TestProcessGraphQLString(testing *testing.T) {
+	adapter := GetGraphQLAdapter()
+	_, err := adapter.ProcessSchemaString("type Broken {")
+	if err == nil {
+		testing.Errorf("Parsing invalid SDL did not fail")
+	}
+
+	schema, err := adapter.ProcessSchemaString("type Query { me: Pet }\ntype Pet { owner: Owner, grid: [[Int]] }")
+	if err != nil || len(schema.Unsupported) != 2 {
+		testing.Errorf("Expecting the undefined Owner type and the nested list to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) != 1 || schema.Models[0].Name != "Pet" {
+		testing.Errorf("Expecting only the Pet model. Got %v", schema.Models)
+	}
+
+	schema, err = adapter.ProcessSchemaString("type Pet { nicknames: [String], toys: [String!], owners: [String!]! }")
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "Pet.nicknames" {
+		testing.Errorf("Expecting the nullable elements of Pet.nicknames to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	expectedProperties := []ModelProperty{
+		{RemoteIdentifier: "nicknames", LocalIdentifier: "nicknames", PropertyType: "string", IsSetType: true, Nullable: true},
+		{RemoteIdentifier: "toys", LocalIdentifier: "toys", PropertyType: "string", IsSetType: true, Nullable: true},
+		{RemoteIdentifier: "owners", LocalIdentifier: "owners", PropertyType: "string", IsSetType: true},
+	}
+	if len(schema.Models) != 1 || !reflect.DeepEqual(schema.Models[0].Properties, expectedProperties) {
+		testing.Errorf("Expecting properties %v. Got %v", expectedProperties, schema.Models)
+	}
+}
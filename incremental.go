@@ -0,0 +1,117 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+)
+
+//RegenerateIncrementally processes the mappings of the context and writes
+//the results with writer, like ProcessMappings followed by
+//WriteGeneratedFiles. A manifest of template, model and output hashes is
+//kept in the output directory; templates whose inputs have not changed
+//since the last run, and whose outputs are still intact on disk, are not
+//executed again. When the writer has CleanStaleFiles set, files recorded
+//by the previous run that are no longer generated are removed.
+func RegenerateIncrementally(context Context, writer *FileSystemWriter) ([]WrittenFile, error) {
+	if len(context.Mappings) == 0 {
+		return []WrittenFile{}, errors.New("No mappings to process")
+	}
+	if err := context.checkFeatureRequirements(); err != nil {
+		return []WrittenFile{}, err
+	}
+	previousManifest, err := ReadManifest(writer.OutputDirectory)
+	if err != nil {
+		return []WrittenFile{}, err
+	}
+	if _, errs := parseTemplates(context); len(errs) > 0 {
+		return []WrittenFile{}, errors.Join(errs...)
+	}
+
+	manifest := Manifest{LibraryVersion: LibraryVersion, TemplateSetHash: templateSetHash(context)}
+	reusableEntries := make(map[string]ManifestEntry)
+	if previousManifest.LibraryVersion == manifest.LibraryVersion && previousManifest.TemplateSetHash == manifest.TemplateSetHash {
+		for _, entry := range previousManifest.Entries {
+			reusableEntries[entry.key()] = entry
+		}
+	}
+
+	writtenFiles := make([]WrittenFile, 0)
+	for _, task := range generationTasks(context) {
+		entry := ManifestEntry{TemplatePath: templatePath(task.Template), TemplateHash: hashBytes(task.Template.Body)}
+		if entry.ModelHash, err = templateDataHash(templateDataFor(context, task.Models)); err != nil {
+			return []WrittenFile{}, err
+		}
+
+		if previousEntry, ok := reusableEntries[entry.key()]; ok && previousEntry.TemplateHash == entry.TemplateHash && writer.manifestFilesIntact(previousEntry.Files) {
+			manifest.Entries = append(manifest.Entries, previousEntry)
+			for _, file := range previousEntry.Files {
+				writtenFiles = append(writtenFiles, WrittenFile{Path: filepath.Join(writer.OutputDirectory, filepath.FromSlash(file.Path)), Status: FileUnchanged})
+			}
+			continue
+		}
+
+		generatedFiles, err := processTemplate(task.Template, context, task.Models)
+		if err != nil {
+			return []WrittenFile{}, err
+		}
+		entry.Files = make([]ManifestFile, 0, len(generatedFiles))
+		for _, generatedFile := range generatedFiles {
+			plan, err := writer.planFile(generatedFile)
+			if err != nil {
+				return writtenFiles, err
+			}
+			writtenFile, err := writer.writePlannedFile(plan)
+			if err != nil {
+				return writtenFiles, err
+			}
+			relativePath, _ := generatedFile.RelativePath()
+			entry.Files = append(entry.Files, ManifestFile{Path: filepath.ToSlash(relativePath), Hash: hashBytes(plan.Body)})
+			writtenFiles = append(writtenFiles, writtenFile)
+		}
+		manifest.Entries = append(manifest.Entries, entry)
+	}
+
+	if writer.CleanStaleFiles {
+		staleFiles, err := FindStaleFiles(writer.OutputDirectory, previousManifest, manifest)
+		if err != nil {
+			return writtenFiles, err
+		}
+		removedPaths, err := RemoveStaleFiles(writer.OutputDirectory, staleFiles)
+		for _, removedPath := range removedPaths {
+			writtenFiles = append(writtenFiles, WrittenFile{Path: filepath.Join(writer.OutputDirectory, filepath.FromSlash(removedPath)), Status: FileRemoved})
+		}
+		if err != nil {
+			return writtenFiles, err
+		}
+	}
+
+	if err := WriteManifest(writer.OutputDirectory, manifest); err != nil {
+		return writtenFiles, err
+	}
+	return writtenFiles, nil
+}
+
+func (self *FileSystemWriter) manifestFilesIntact(files []ManifestFile) bool {
+	for _, file := range files {
+		fileContents, err := ioutil.ReadFile(filepath.Join(self.OutputDirectory, filepath.FromSlash(file.Path)))
+		if err != nil || hashBytes(fileContents) != file.Hash {
+			return false
+		}
+	}
+	return true
+}
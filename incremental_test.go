@@ -0,0 +1,113 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+type countingAdapter struct {
+	GoTemplateAdapter
+	GenerateCount int
+}
+
+func (self *countingAdapter) GenerateFiles(templateInfo TemplateInfo, templateData TemplateData) ([]GeneratedFile, error) {
+	self.GenerateCount++
+	return self.GoTemplateAdapter.GenerateFiles(templateInfo, templateData)
+}
+
+func TestRegenerateIncrementally(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+
+	adapter := &countingAdapter{}
+	SetupContext()
+	context.AddTemplate(templateFileName, []byte(templateBody), testTemplaterVersion, "template", adapter)
+	SetupModel()
+	context.AddTemplatesForModelsMapping([]string{templateFileName}, []string{modelName})
+
+	writtenFiles, err := RegenerateIncrementally(context, &writer)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 1 {
+		testing.Errorf("Expecting %v template executions. Got %v", 1, adapter.GenerateCount)
+	}
+	if len(writtenFiles) != 1 || writtenFiles[0].Status != FileCreated {
+		testing.Fatalf("Expecting one created file. Got %v", writtenFiles)
+	}
+	manifest, err := ReadManifest(outputDirectory)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(manifest.Entries) != 1 || len(manifest.Entries[0].Files) != 1 {
+		testing.Fatalf("Unexpected manifest %v", manifest)
+	}
+
+	//Nothing changed, so the template should not run again
+	if writtenFiles, err = RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 1 {
+		testing.Errorf("Expecting %v template executions. Got %v", 1, adapter.GenerateCount)
+	}
+	if len(writtenFiles) != 1 || writtenFiles[0].Status != FileUnchanged {
+		testing.Errorf("Expecting one unchanged file. Got %v", writtenFiles)
+	}
+
+	//Editing the output on disk forces the template to run again
+	ioutil.WriteFile(writtenFiles[0].Path, []byte("edited"), 0644)
+	if writtenFiles, err = RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 2 {
+		testing.Errorf("Expecting %v template executions. Got %v", 2, adapter.GenerateCount)
+	}
+	if len(writtenFiles) != 1 || writtenFiles[0].Status != FileOverwritten {
+		testing.Errorf("Expecting one overwritten file. Got %v", writtenFiles)
+	}
+
+	//Changing the models forces the template to run again
+	context.Schema.Models[0].AddProperty(propertyRemoteIdent, propertyLocalIdent, propertyPropertyType)
+	context.Mappings[0].Models[0] = &context.Schema.Models[0]
+	if writtenFiles, err = RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 3 {
+		testing.Errorf("Expecting %v template executions. Got %v", 3, adapter.GenerateCount)
+	}
+	//The template ignores properties, so the file itself is left alone
+	if len(writtenFiles) != 1 || writtenFiles[0].Status != FileUnchanged {
+		testing.Errorf("Expecting one unchanged file. Got %v", writtenFiles)
+	}
+
+	//Changing the custom type mappings forces the template to run again
+	context.AddCustomType("java", map[string]string{"string": "String"})
+	if writtenFiles, err = RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 4 {
+		testing.Errorf("Expecting %v template executions. Got %v", 4, adapter.GenerateCount)
+	}
+	if _, err = RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if adapter.GenerateCount != 4 {
+		testing.Errorf("Expecting %v template executions. Got %v", 4, adapter.GenerateCount)
+	}
+}
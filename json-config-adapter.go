@@ -0,0 +1,164 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+type JSONConfigAdapter struct{}
+
+type codeGenConfig struct {
+	TemplaterVersion    string
+	BasePackage         string
+	Language            string
+	ModelSchemaFileName string
+	TemplatesDirectory  string
+	Mappings            []codeGenConfigMapping
+}
+
+type codeGenConfigMapping struct {
+	ModelNames    []string
+	TemplateNames []string
+}
+
+//ProcessConfigurationFile reads YAML (.yaml, .yml) and TOML (.toml)
+//configuration files as well as JSON ones. The model schema file the
+//configuration names may likewise be in any of the three formats. Relative
+//paths in the file are relative to the directory holding it.
+func (self *JSONConfigAdapter) ProcessConfigurationFile(configFile *os.File) (Context, error) {
+	if configFile == nil {
+		return Context{}, errors.New("Configuration file must not be nil")
+	}
+	fileContents, err := ioutil.ReadAll(configFile)
+	if err != nil {
+		return Context{}, err
+	}
+	return self.parseConfiguration(FormatForPath(configFile.Name()), fileContents, filepath.Dir(configFile.Name()))
+}
+
+func (self *JSONConfigAdapter) ProcessConfigurationString(configString string) (Context, error) {
+	return self.ParseConfigurationString([]byte(configString))
+}
+
+func (self *JSONConfigAdapter) ParseConfigurationString(configString []byte) (Context, error) {
+	return self.parseConfigurationJSON(configString, "")
+}
+
+func (self *JSONConfigAdapter) ParseConfigurationYAML(configYAML []byte) (Context, error) {
+	return self.parseConfiguration(FormatYAML, configYAML, "")
+}
+
+func (self *JSONConfigAdapter) ParseConfigurationTOML(configTOML []byte) (Context, error) {
+	return self.parseConfiguration(FormatTOML, configTOML, "")
+}
+
+func (self *JSONConfigAdapter) parseConfiguration(format string, configDocument []byte, directory string) (Context, error) {
+	configJSON, err := convertToJSON(format, configDocument)
+	if err != nil {
+		return Context{}, err
+	}
+	return self.parseConfigurationJSON(configJSON, directory)
+}
+
+//parseConfigurationJSON builds a Context from a JSON configuration whose
+//relative paths are relative to directory, or to the working directory
+//when it is empty.
+func (self *JSONConfigAdapter) parseConfigurationJSON(configJSON []byte, directory string) (Context, error) {
+	var config codeGenConfig
+	err := json.Unmarshal(configJSON, &config)
+	if err != nil {
+		return Context{}, err
+	}
+	config.ModelSchemaFileName = resolveConfigPath(directory, config.ModelSchemaFileName)
+	config.TemplatesDirectory = resolveConfigPath(directory, config.TemplatesDirectory)
+	return config.buildContext()
+}
+
+func resolveConfigPath(directory string, path string) string {
+	if directory == "" || path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(directory, path)
+}
+
+func (self *codeGenConfig) validate() error {
+	if self.ModelSchemaFileName == "" {
+		return errors.New("Configuration is missing ModelSchemaFileName")
+	}
+	if self.TemplatesDirectory == "" {
+		return errors.New("Configuration is missing TemplatesDirectory")
+	}
+	if len(self.Mappings) == 0 {
+		return errors.New("Configuration has no Mappings")
+	}
+	for index, mapping := range self.Mappings {
+		if len(mapping.TemplateNames) == 0 {
+			return errors.New("Mapping " + strconv.Itoa(index) + " has no TemplateNames")
+		}
+	}
+	return nil
+}
+
+func (self *codeGenConfig) buildContext() (Context, error) {
+	if err := self.validate(); err != nil {
+		return Context{}, err
+	}
+
+	context := BeginContext()
+	if self.TemplaterVersion != "" {
+		context.TemplaterVersion = self.TemplaterVersion
+	}
+	if self.BasePackage != "" {
+		context.PackageName = self.BasePackage
+	}
+	context.Language = self.Language
+
+	schemaAdapter := GetJSONSchemaAdapter()
+	schema, err := schemaAdapter.ProcessSchemaFile(self.ModelSchemaFileName)
+	if err != nil {
+		return Context{}, err
+	}
+	for _, model := range schema.Models {
+		if _, err := context.AddModel(model); err != nil {
+			return Context{}, err
+		}
+	}
+	for _, enum := range schema.Enums {
+		if _, err := context.AddEnum(enum); err != nil {
+			return Context{}, err
+		}
+	}
+	context.Schema.Project = schema.Project
+	if schema.Project != "" {
+		context.ProjectName = schema.Project
+	}
+
+	if _, err := context.AddTemplateDirectory(self.TemplatesDirectory); err != nil {
+		return Context{}, err
+	}
+
+	for _, mapping := range self.Mappings {
+		if err := context.AddTemplatesForModelsMapping(mapping.TemplateNames, mapping.ModelNames); err != nil {
+			return Context{}, err
+		}
+	}
+	return context, nil
+}
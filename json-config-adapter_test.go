@@ -0,0 +1,189 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseConfigurationString(testing *testing.T) {
+	//Test valid and correct configuration JSON
+	configAdapter := GetJSONConfigurationAdapter()
+	configContext, err := configAdapter.ParseConfigurationString(TestValidCorrectConfigString)
+	if err != nil {
+		testing.Errorf("Error while parsing valid JSON configuration: %v", err.Error())
+	} else {
+		if configContext.PackageName != TestBasePackage {
+			testing.Errorf("Expecting package %v. Got %v", TestBasePackage, configContext.PackageName)
+		}
+		if configContext.Language != TestLanguage {
+			testing.Errorf("Expecting language %v. Got %v", TestLanguage, configContext.Language)
+		}
+		if configContext.TemplaterVersion != TestVersion {
+			testing.Errorf("Expecting templater version %v. Got %v", TestVersion, configContext.TemplaterVersion)
+		}
+		if configContext.ProjectName != TestProjectName {
+			testing.Errorf("Expecting project %v. Got %v", TestProjectName, configContext.ProjectName)
+		}
+		if len(configContext.Schema.Models) != 2 {
+			testing.Errorf("Expecting %v models. Got %v", 2, len(configContext.Schema.Models))
+		}
+		if len(configContext.Templates) != 3 {
+			testing.Errorf("Expecting %v templates. Got %v", 3, len(configContext.Templates))
+		}
+		if len(configContext.Mappings) != 2 {
+			testing.Errorf("Expecting %v mappings. Got %v", 2, len(configContext.Mappings))
+		} else if len(configContext.Mappings[0].Models) != 2 {
+			testing.Errorf("Expecting %v models in first mapping. Got %v", 2, len(configContext.Mappings[0].Models))
+		} else if configContext.Mappings[0].Templates[0].FileName != TestTemplateName01 {
+			testing.Errorf("Expecting template %v. Got %v", TestTemplateName01, configContext.Mappings[0].Templates[0].FileName)
+		}
+	}
+
+	//Test the same configuration passed as a string
+	if _, err = configAdapter.ProcessConfigurationString(string(TestValidCorrectConfigString)); err != nil {
+		testing.Errorf("Error while processing valid JSON configuration string: %v", err.Error())
+	}
+
+	//Test invalid JSON
+	if _, err = configAdapter.ParseConfigurationString(TestInvalidJSONString); err == nil {
+		testing.Errorf("ParseConfigurationString did not fail when passed invalid JSON")
+	}
+
+	//Test valid but incorrect configuration JSON
+	if _, err = configAdapter.ParseConfigurationString(TestValidIncorrectConfigString); err == nil {
+		testing.Errorf("ParseConfigurationString did not fail when passed incorrect JSON")
+	}
+}
+
+func TestProcessConfigurationFile(testing *testing.T) {
+	configAdapter := GetJSONConfigurationAdapter()
+
+	configFile, err := os.Open("test-resources/code-gen-config.json")
+	if err != nil {
+		testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+	}
+	defer configFile.Close()
+	configContext, err := configAdapter.ProcessConfigurationFile(configFile)
+	if err != nil {
+		testing.Errorf("Error while processing valid configuration file: %v", err.Error())
+	} else if configContext.TemplaterVersion != "1.0" {
+		testing.Errorf("Expecting templater version %v. Got %v", "1.0", configContext.TemplaterVersion)
+	}
+
+	incorrectFile, err := os.Open("test-resources/code-gen-config-incorrect.json")
+	if err != nil {
+		testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+	}
+	defer incorrectFile.Close()
+	if _, err = configAdapter.ProcessConfigurationFile(incorrectFile); err == nil {
+		testing.Errorf("ProcessConfigurationFile did not fail when passed a mapping without templates")
+	}
+
+	invalidFile, err := os.Open("test-resources/code-gen-config-invalid.json")
+	if err != nil {
+		testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+	}
+	defer invalidFile.Close()
+	if _, err = configAdapter.ProcessConfigurationFile(invalidFile); err == nil {
+		testing.Errorf("ProcessConfigurationFile did not fail when passed invalid JSON")
+	}
+
+	if _, err = configAdapter.ProcessConfigurationFile(nil); err == nil {
+		testing.Errorf("ProcessConfigurationFile did not fail when passed a nil file")
+	}
+}
+
+func TestProcessConfigurationFileFormats(testing *testing.T) {
+	configAdapter := GetJSONConfigurationAdapter()
+
+	jsonFile, err := os.Open("test-resources/code-gen-config.json")
+	if err != nil {
+		testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+	}
+	defer jsonFile.Close()
+	jsonContext, err := configAdapter.ProcessConfigurationFile(jsonFile)
+	if err != nil {
+		testing.Fatalf("Error while processing JSON configuration file: %v", err.Error())
+	}
+
+	for _, configPath := range []string{"test-resources/code-gen-config.yaml", "test-resources/code-gen-config.toml"} {
+		configFile, err := os.Open(configPath)
+		if err != nil {
+			testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+		}
+		defer configFile.Close()
+		configContext, err := configAdapter.ProcessConfigurationFile(configFile)
+		if err != nil {
+			testing.Errorf("Error while processing %v: %v", configPath, err.Error())
+			continue
+		}
+		if configContext.PackageName != jsonContext.PackageName || configContext.Language != jsonContext.Language || configContext.TemplaterVersion != jsonContext.TemplaterVersion {
+			testing.Errorf("Expecting %v %v %v. Got %v %v %v", jsonContext.PackageName, jsonContext.Language, jsonContext.TemplaterVersion, configContext.PackageName, configContext.Language, configContext.TemplaterVersion)
+		}
+		if !reflect.DeepEqual(configContext.Schema, jsonContext.Schema) {
+			testing.Errorf("Expecting schema %v. Got %v", jsonContext.Schema, configContext.Schema)
+		}
+		if !reflect.DeepEqual(configContext.Templates, jsonContext.Templates) {
+			testing.Errorf("Expecting %v templates. Got %v", len(jsonContext.Templates), len(configContext.Templates))
+		}
+		if !reflect.DeepEqual(configContext.Mappings, jsonContext.Mappings) {
+			testing.Errorf("Expecting mappings %v. Got %v", jsonContext.Mappings, configContext.Mappings)
+		}
+	}
+
+	//Test invalid YAML and TOML
+	if _, err = configAdapter.ParseConfigurationYAML([]byte("Mappings: [unclosed")); err == nil {
+		testing.Errorf("ParseConfigurationYAML did not fail when passed invalid YAML")
+	}
+	if _, err = configAdapter.ParseConfigurationTOML([]byte("Mappings = [unclosed")); err == nil {
+		testing.Errorf("ParseConfigurationTOML did not fail when passed invalid TOML")
+	}
+}
+
+func TestProcessConfigurationFileFromAnotherDirectory(testing *testing.T) {
+	configPath, err := filepath.Abs("test-resources/code-gen-config.json")
+	if err != nil {
+		testing.Fatalf("Unable to find configuration fixture: %v", err.Error())
+	}
+	workingDirectory, err := os.Getwd()
+	if err != nil {
+		testing.Fatalf("Unable to read the working directory: %v", err.Error())
+	}
+	if err := os.Chdir(os.TempDir()); err != nil {
+		testing.Fatalf("Unable to change directory: %v", err.Error())
+	}
+	defer os.Chdir(workingDirectory)
+
+	configFile, err := os.Open(configPath)
+	if err != nil {
+		testing.Fatalf("Unable to open configuration fixture: %v", err.Error())
+	}
+	defer configFile.Close()
+	configAdapter := GetJSONConfigurationAdapter()
+	configContext, err := configAdapter.ProcessConfigurationFile(configFile)
+	if err != nil {
+		testing.Fatalf("Error while processing %v from %v: %v", configPath, os.TempDir(), err.Error())
+	}
+	if len(configContext.Schema.Models) != 2 {
+		testing.Errorf("Expecting %v models. Got %v", 2, len(configContext.Schema.Models))
+	}
+	if len(configContext.Templates) != 3 {
+		testing.Errorf("Expecting %v templates. Got %v", 3, len(configContext.Templates))
+	}
+}
@@ -0,0 +1,272 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math"
+	"time"
+
+	"bitbucket.org/pkg/inflect"
+)
+
+//JSONSampleAdapter infers a Schema from example JSON documents, such as
+//API responses. The top level object, or each object of a top level array,
+//is a sample of the model named RootModelName. Nested objects become models
+//named after their key in Titlecase, singular for arrays, so "tags" holding
+//objects becomes Tag. Objects under the same key anywhere share a model.
+//Arrays set IsSetType. Properties missing from some samples are Optional
+//and those null in any are Nullable.
+type JSONSampleAdapter struct {
+	RootModelName string
+}
+
+func (self *JSONSampleAdapter) ProcessSchemaFile(samplePath string) (Schema, error) {
+	return self.ProcessSampleFiles(samplePath)
+}
+
+func (self *JSONSampleAdapter) ProcessSchemaString(sample string) (Schema, error) {
+	return self.InferSchema([]byte(sample))
+}
+
+func (self *JSONSampleAdapter) ProcessSampleFiles(samplePaths ...string) (Schema, error) {
+	samples := make([][]byte, 0, len(samplePaths))
+	for _, samplePath := range samplePaths {
+		fileContents, err := ioutil.ReadFile(samplePath)
+		if err != nil {
+			return Schema{}, err
+		}
+		samples = append(samples, fileContents)
+	}
+	return self.InferSchema(samples...)
+}
+
+//InferSchema merges every sample into one Schema. Values whose type can't
+//be inferred are listed in its Unsupported field: keys that are always
+//null or empty arrays, nested arrays, or keys whose values have
+//conflicting types.
+func (self *JSONSampleAdapter) InferSchema(samples ...[]byte) (Schema, error) {
+	if len(samples) == 0 {
+		return Schema{}, errors.New("At least one sample is required")
+	}
+	rootModelName := self.RootModelName
+	if rootModelName == "" {
+		rootModelName = "Root"
+	}
+
+	inferrer := &sampleInferrer{Models: make(map[string]*sampleModel)}
+	root := inferrer.model(rootModelName)
+	for _, sample := range samples {
+		document, err := decodeDocumentJSON(sample)
+		if err != nil {
+			return Schema{}, err
+		}
+		objects := []interface{}{document}
+		if array, ok := document.([]interface{}); ok {
+			objects = array
+		}
+		for _, object := range objects {
+			typedObject, ok := object.(*documentObject)
+			if !ok {
+				return Schema{}, errors.New("Samples must be JSON objects or arrays of objects")
+			}
+			inferrer.addSample(root, typedObject)
+		}
+	}
+
+	schema := inferrer.schema()
+	if err := schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	schema.Unsupported = inferrer.Unsupported
+	return schema, nil
+}
+
+type sampleModel struct {
+	Name        string
+	SampleCount int
+	Keys        []string
+	Fields      map[string]*sampleField
+}
+
+//sampleField accumulates what the samples show about one key. Type is empty
+//until a value other than null or an empty array is seen.
+type sampleField struct {
+	Type       string
+	IsSetType  bool
+	IsScalar   bool
+	Count      int
+	Null       bool
+	Conflicted bool
+}
+
+type sampleInferrer struct {
+	ModelNames  []string
+	Models      map[string]*sampleModel
+	Unsupported []UnsupportedConstruct
+}
+
+func (self *sampleInferrer) model(name string) *sampleModel {
+	if model, ok := self.Models[name]; ok {
+		return model
+	}
+	model := &sampleModel{Name: name, Keys: make([]string, 0), Fields: make(map[string]*sampleField)}
+	self.ModelNames = append(self.ModelNames, name)
+	self.Models[name] = model
+	return model
+}
+
+func (self *sampleInferrer) addSample(model *sampleModel, object *documentObject) {
+	model.SampleCount++
+	for _, key := range object.Keys {
+		field, ok := model.Fields[key]
+		if !ok {
+			field = &sampleField{}
+			model.Keys = append(model.Keys, key)
+			model.Fields[key] = field
+		}
+		field.Count++
+		path := model.Name + "." + key
+		value := object.Values[key]
+		if value == nil {
+			field.Null = true
+			continue
+		}
+		if array, ok := value.([]interface{}); ok {
+			if !field.IsSetType && field.Type != "" {
+				self.conflict(field, path, "values are arrays in some samples but not others")
+			}
+			field.IsSetType = true
+			for _, element := range array {
+				if _, ok := element.([]interface{}); ok {
+					self.conflict(field, path, "nested arrays")
+					continue
+				}
+				if element != nil {
+					self.observe(field, path, Titlecase(inflect.Singularize(key)), element)
+				}
+			}
+			continue
+		}
+		if field.IsSetType {
+			self.conflict(field, path, "values are arrays in some samples but not others")
+			continue
+		}
+		self.observe(field, path, Titlecase(key), value)
+	}
+}
+
+func (self *sampleInferrer) observe(field *sampleField, path string, modelName string, value interface{}) {
+	if field.Conflicted {
+		return
+	}
+	if object, ok := value.(*documentObject); ok {
+		if field.Type != "" && (field.IsScalar || field.Type != modelName) {
+			self.conflict(field, path, "values are objects in some samples but not others")
+			return
+		}
+		field.Type = modelName
+		self.addSample(self.model(modelName), object)
+		return
+	}
+
+	valueType := sampleValueType(value)
+	if field.Type != "" && !field.IsScalar {
+		self.conflict(field, path, "values are objects in some samples but not others")
+		return
+	}
+	mergedType, ok := mergeSampleTypes(field.Type, valueType)
+	if !ok {
+		self.conflict(field, path, "values are "+field.Type+" in some samples and "+valueType+" in others")
+		return
+	}
+	field.Type = mergedType
+	field.IsScalar = true
+}
+
+func (self *sampleInferrer) conflict(field *sampleField, path string, reason string) {
+	if !field.Conflicted {
+		field.Conflicted = true
+		self.Unsupported = append(self.Unsupported, UnsupportedConstruct{Path: path, Keyword: "type", Reason: reason})
+	}
+}
+
+func (self *sampleInferrer) schema() Schema {
+	schema := Schema{Models: make([]Model, 0, len(self.ModelNames)), Enums: make([]Enum, 0)}
+	for _, name := range self.ModelNames {
+		sample := self.Models[name]
+		model := Model{Name: name, Properties: make([]ModelProperty, 0, len(sample.Keys))}
+		for _, key := range sample.Keys {
+			field := sample.Fields[key]
+			if field.Conflicted {
+				continue
+			}
+			if field.Type == "" {
+				self.Unsupported = append(self.Unsupported, UnsupportedConstruct{Path: name + "." + key, Keyword: "type", Reason: "values are always null or empty arrays"})
+				continue
+			}
+			optional := field.Count < sample.SampleCount
+			model.Properties = append(model.Properties, ModelProperty{RemoteIdentifier: key, LocalIdentifier: key, PropertyType: field.Type, IsSetType: field.IsSetType, Optional: optional, Nullable: field.Null})
+		}
+		schema.Models = append(schema.Models, model)
+	}
+	return schema
+}
+
+func sampleValueType(value interface{}) string {
+	switch typedValue := value.(type) {
+	case bool:
+		return "boolean"
+	case json.Number:
+		if integer, err := typedValue.Int64(); err == nil {
+			if integer > math.MaxInt32 || integer < math.MinInt32 {
+				return "long"
+			}
+			return "int"
+		}
+		return "float"
+	case string:
+		if _, err := time.Parse(time.RFC3339, typedValue); err == nil {
+			return "date"
+		}
+		if _, err := time.Parse("2006-01-02", typedValue); err == nil {
+			return "date"
+		}
+		return "string"
+	}
+	return "string"
+}
+
+//mergeSampleTypes returns the type that holds values of both types, e.g.
+//float for int and float.
+func mergeSampleTypes(existingType string, newType string) (string, bool) {
+	if existingType == "" || existingType == newType {
+		return newType, true
+	}
+	numericRank := map[string]int{"int": 1, "long": 2, "float": 3}
+	if numericRank[existingType] > 0 && numericRank[newType] > 0 {
+		if numericRank[newType] > numericRank[existingType] {
+			return newType, true
+		}
+		return existingType, true
+	}
+	textual := map[string]bool{"string": true, "date": true}
+	if textual[existingType] && textual[newType] {
+		return "string", true
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessSampleFiles(testing *testing.T) {
+	adapter := GetJSONSampleAdapter("Movie")
+	schema, err := adapter.ProcessSampleFiles("test-resources/sample-movie-01.json", "test-resources/sample-movie-02.json")
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "Movie.sequel" {
+		testing.Errorf("Expecting the always null Movie.sequel to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+
+	expectedModels := []Model{
+		{Name: "Movie", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "int"},
+			{RemoteIdentifier: "title", LocalIdentifier: "title", PropertyType: "string"},
+			{RemoteIdentifier: "released", LocalIdentifier: "released", PropertyType: "date"},
+			{RemoteIdentifier: "rating", LocalIdentifier: "rating", PropertyType: "float"},
+			{RemoteIdentifier: "director", LocalIdentifier: "director", PropertyType: "Director", Nullable: true},
+			{RemoteIdentifier: "cast", LocalIdentifier: "cast", PropertyType: "Cast", IsSetType: true},
+			{RemoteIdentifier: "genres", LocalIdentifier: "genres", PropertyType: "string", IsSetType: true},
+		}},
+		{Name: "Director", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"},
+		}},
+		{Name: "Cast", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"},
+			{RemoteIdentifier: "role", LocalIdentifier: "role", PropertyType: "string", Optional: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+}
+
+func TestInferSchemaConflicts(testing *testing.T) {
+	adapter := GetJSONSampleAdapter("")
+	schema, err := adapter.InferSchema([]byte(`{"count": 1, "owner": {"id": 1}}`), []byte(`{"count": "many", "owner": [{"id": 2}]}`))
+	if err != nil || len(schema.Unsupported) != 2 {
+		testing.Errorf("Expecting conflicting count and owner values to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) == 0 || schema.Models[0].Name != "Root" || len(schema.Models[0].Properties) != 0 {
+		testing.Errorf("Expecting a Root model without properties. Got %v", schema.Models)
+	}
+
+	_, err = adapter.ProcessSchemaString(`[1, 2, 3]`)
+	if err == nil {
+		testing.Errorf("Inferring a schema from an array of numbers did not fail")
+	}
+}
@@ -15,6 +15,7 @@ limitations under the License.*/
 package levo
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -22,29 +23,59 @@ import (
 
 type JSONSchemaAdapter struct{}
 
+//ProcessSchemaFile reads YAML (.yaml, .yml) and TOML (.toml) schema files
+//as well as JSON ones.
 func (self *JSONSchemaAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
 	fileContents, err := ioutil.ReadFile(schemaPath)
 	if err != nil {
 		return Schema{}, err
 	}
-	return self.ParseModelSchemaString(fileContents)
+	return self.parseModelSchema(FormatForPath(schemaPath), fileContents)
+}
+
+func (self *JSONSchemaAdapter) ParseModelSchemaYAML(schemaYAML []byte) (Schema, error) {
+	return self.parseModelSchema(FormatYAML, schemaYAML)
+}
+
+func (self *JSONSchemaAdapter) ParseModelSchemaTOML(schemaTOML []byte) (Schema, error) {
+	return self.parseModelSchema(FormatTOML, schemaTOML)
+}
+
+func (self *JSONSchemaAdapter) parseModelSchema(format string, schemaDocument []byte) (Schema, error) {
+	schemaJSON, err := convertToJSON(format, schemaDocument)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.ParseModelSchemaString(schemaJSON)
 }
 
 func (self *JSONSchemaAdapter) ParseModelSchemaString(schemaString []byte) (Schema, error) {
 	fmt.Printf("")
 	var schema Schema
-	err := json.Unmarshal(schemaString, &schema)
+	//Keep integer Defaults exact
+	decoder := json.NewDecoder(bytes.NewReader(schemaString))
+	decoder.UseNumber()
+	err := decoder.Decode(&schema)
 	if err != nil {
 		return Schema{}, err
 	}
 	if err := schema.validate(); err != nil {
 		return Schema{}, err
 	}
+	for enumIndex, enum := range schema.Enums {
+		if enum.Type == "" {
+			schema.Enums[enumIndex].Type = "string"
+		}
+	}
 	for modelIndex, model := range schema.Models {
 		for propIndex, prop := range model.Properties {
 			if prop.LocalIdentifier == "" {
 				schema.Models[modelIndex].Properties[propIndex].LocalIdentifier = prop.RemoteIdentifier
 			}
+			if prop.Default != nil {
+				//Already checked by validate
+				schema.Models[modelIndex].Properties[propIndex].Default, _ = schema.typedDefault(prop)
+			}
 		}
 	}
 	return schema, nil
@@ -16,6 +16,7 @@ package levo
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 )
 
@@ -43,7 +44,7 @@ func TestParseModelSchemaString(testing *testing.T) {
 	modelSchema, err := schemaAdapter.ParseModelSchemaString(TestValidSchemaString)
 	models := modelSchema.Models
 	if err != nil {
-		testing.Errorf("Error while parsing valid JSON schema: ", err.Error())
+		testing.Errorf("Error while parsing valid JSON schema: %v", err.Error())
 	}
 	if len(models) != 2 {
 		testing.Errorf("Expecting %v models. Got %v", 2, len(models))
@@ -77,3 +78,126 @@ func TestParseModelSchemaString(testing *testing.T) {
 		testing.Errorf("ParseModelSchemaString did not fail when passed incorrect JSON")
 	}
 }
+
+func TestProcessSchemaFileFormats(testing *testing.T) {
+	schemaAdapter := GetJSONSchemaAdapter()
+	jsonSchema, err := schemaAdapter.ProcessSchemaFile("test-resources/model-schema.json")
+	if err != nil {
+		testing.Fatalf("Error while processing JSON schema file: %v", err.Error())
+	}
+	for _, schemaPath := range []string{"test-resources/model-schema.yaml", "test-resources/model-schema.toml"} {
+		schema, err := schemaAdapter.ProcessSchemaFile(schemaPath)
+		if err != nil {
+			testing.Errorf("Error while processing %v: %v", schemaPath, err.Error())
+		} else if !reflect.DeepEqual(schema, jsonSchema) {
+			testing.Errorf("Expecting %v. Got %v", jsonSchema, schema)
+		}
+	}
+
+	//Test invalid YAML and TOML
+	if _, err = schemaAdapter.ParseModelSchemaYAML([]byte("Models: [unclosed")); err == nil {
+		testing.Errorf("ParseModelSchemaYAML did not fail when passed invalid YAML")
+	}
+	if _, err = schemaAdapter.ParseModelSchemaTOML([]byte("Models = [unclosed")); err == nil {
+		testing.Errorf("ParseModelSchemaTOML did not fail when passed invalid TOML")
+	}
+}
+
+func TestFormatForPath(testing *testing.T) {
+	expectedFormats := map[string]string{
+		"schema.json":  FormatJSON,
+		"schema.yaml":  FormatYAML,
+		"schema.YML":   FormatYAML,
+		"schema.toml":  FormatTOML,
+		"schema":       FormatJSON,
+		"dir.yaml/sch": FormatJSON,
+	}
+	for path, expectedFormat := range expectedFormats {
+		if format := FormatForPath(path); format != expectedFormat {
+			testing.Errorf("Expecting %v. Got %v", expectedFormat, format)
+		}
+	}
+}
+
+func TestParseModelSchemaStringWithEnums(testing *testing.T) {
+	schemaAdapter := GetJSONSchemaAdapter()
+	schemaJSON := []byte(`{"Project":"test","Models":[{"Name":"Car","Properties":[{"RemoteIdentifier":"color","PropertyType":"Color"}]}],"Enums":[{"Name":"Color","Values":[{"Name":"Red"},{"Name":"Green","RawValue":"green"}]}]}`)
+	schema, err := schemaAdapter.ParseModelSchemaString(schemaJSON)
+	if err != nil {
+		testing.Fatalf("Error while parsing valid JSON schema: %v", err.Error())
+	}
+	expectedEnums := []Enum{{Name: "Color", Type: "string", Values: []EnumValue{{Name: "Red"}, {Name: "Green", RawValue: "green"}}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting %v. Got %v", expectedEnums, schema.Enums)
+	}
+
+	//Test a property referring to an enum with the wrong case
+	schemaJSON = []byte(`{"Project":"test","Models":[{"Name":"Car","Properties":[{"RemoteIdentifier":"color","PropertyType":"color"}]}],"Enums":[{"Name":"Color"}]}`)
+	if _, err = schemaAdapter.ParseModelSchemaString(schemaJSON); err == nil {
+		testing.Errorf("ParseModelSchemaString did not fail when passed a misspelt enum reference")
+	}
+}
+
+func TestParseModelSchemaStringWithRelationships(testing *testing.T) {
+	schemaAdapter := GetJSONSchemaAdapter()
+	schemaJSON := []byte(`{"Project":"test","Models":[{"Name":"Person","PrimaryKey":["login"],"Properties":[{"RemoteIdentifier":"pets","PropertyType":"Pet","IsSetType":true,"Relationship":{"Kind":"hasMany","Inverse":"owner","OnDelete":"cascade"}},{"RemoteIdentifier":"login","PropertyType":"string"}]},{"Name":"Pet","Properties":[{"RemoteIdentifier":"owner","PropertyType":"Person","Relationship":{"Kind":"belongsTo","Inverse":"pets"}}]}]}`)
+	schema, err := schemaAdapter.ParseModelSchemaString(schemaJSON)
+	if err != nil {
+		testing.Fatalf("Error while parsing valid JSON schema: %v", err.Error())
+	}
+	expectedRelationship := Relationship{Kind: HasMany, Inverse: "owner", OnDelete: DeleteCascade}
+	if relationship := schema.Models[0].Properties[0].Relationship; relationship != expectedRelationship {
+		testing.Errorf("Expecting %v. Got %v", expectedRelationship, relationship)
+	}
+
+	//Test a relationship whose inverse does not point back
+	schemaJSON = []byte(`{"Project":"test","Models":[{"Name":"Person","Properties":[{"RemoteIdentifier":"pets","PropertyType":"Pet","IsSetType":true,"Relationship":{"Kind":"hasMany","Inverse":"owner"}}]},{"Name":"Pet","Properties":[{"RemoteIdentifier":"owner","PropertyType":"Pet","Relationship":{"Kind":"belongsTo"}}]}]}`)
+	if _, err = schemaAdapter.ParseModelSchemaString(schemaJSON); err == nil {
+		testing.Errorf("ParseModelSchemaString did not fail when passed a mismatched inverse relationship")
+	}
+}
+
+func TestParseModelSchemaStringWithDefaults(testing *testing.T) {
+	schemaAdapter := GetJSONSchemaAdapter()
+	schemaJSON := []byte(`{"Project":"test","Models":[{"Name":"Car","Properties":[{"RemoteIdentifier":"wheels","PropertyType":"long","Default":9007199254740993},{"RemoteIdentifier":"ratio","PropertyType":"float","Default":2},{"RemoteIdentifier":"name","PropertyType":"string","Optional":true,"Nullable":true,"Default":"car"}]}]}`)
+	schema, err := schemaAdapter.ParseModelSchemaString(schemaJSON)
+	if err != nil {
+		testing.Fatalf("Error while parsing valid JSON schema: %v", err.Error())
+	}
+	expectedProperties := []ModelProperty{
+		{RemoteIdentifier: "wheels", LocalIdentifier: "wheels", PropertyType: "long", Default: int64(9007199254740993)},
+		{RemoteIdentifier: "ratio", LocalIdentifier: "ratio", PropertyType: "float", Default: float64(2)},
+		{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string", Optional: true, Nullable: true, Default: "car"},
+	}
+	if !reflect.DeepEqual(schema.Models[0].Properties, expectedProperties) {
+		testing.Errorf("Expecting %v. Got %v", expectedProperties, schema.Models[0].Properties)
+	}
+
+	//Test a Default of the wrong type
+	schemaJSON = []byte(`{"Project":"test","Models":[{"Name":"Car","Properties":[{"RemoteIdentifier":"wheels","PropertyType":"int","Default":"four"}]}]}`)
+	if _, err = schemaAdapter.ParseModelSchemaString(schemaJSON); err == nil {
+		testing.Errorf("ParseModelSchemaString did not fail when passed a Default of the wrong type")
+	}
+}
+
+func TestParseModelSchemaStringWithKeys(testing *testing.T) {
+	schemaAdapter := GetJSONSchemaAdapter()
+	schemaJSON := []byte(`{"Project":"test","Models":[{"Name":"Line","PrimaryKey":["order","number"],"Indexes":[{"Name":"by_sku","Properties":["sku"],"Unique":true}],"Properties":[{"RemoteIdentifier":"order","PropertyType":"long"},{"RemoteIdentifier":"number","PropertyType":"int"},{"RemoteIdentifier":"sku","PropertyType":"string"}]}]}`)
+	schema, err := schemaAdapter.ParseModelSchemaString(schemaJSON)
+	if err != nil {
+		testing.Fatalf("Error while parsing valid JSON schema: %v", err.Error())
+	}
+	if PrimaryKeyProp(schema.Models[0]) != "ORDER" {
+		testing.Errorf("Expecting %v. Got %v", "ORDER", PrimaryKeyProp(schema.Models[0]))
+	}
+	expectedIndexes := []Index{{Name: "by_sku", Properties: []string{"sku"}, Unique: true}}
+	if !reflect.DeepEqual(schema.Models[0].Indexes, expectedIndexes) {
+		testing.Errorf("Expecting %v. Got %v", expectedIndexes, schema.Models[0].Indexes)
+	}
+
+	//Test a primary key naming a missing property
+	schemaJSON = []byte(`{"Project":"test","Models":[{"Name":"Line","PrimaryKey":["id"],"Properties":[{"RemoteIdentifier":"sku","PropertyType":"string"}]}]}`)
+	if _, err = schemaAdapter.ParseModelSchemaString(schemaJSON); err == nil {
+		testing.Errorf("ParseModelSchemaString did not fail when passed an unresolvable primary key")
+	}
+}
@@ -0,0 +1,501 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+
+	"bitbucket.org/pkg/inflect"
+)
+
+//Keywords that change the shape of the data in ways levo models can't
+//express. Validation keywords such as minimum or pattern don't change the
+//shape and are ignored.
+var unsupportedJSONSchemaKeywords []string = []string{"not", "if", "then", "else", "const", "patternProperties", "dependentSchemas", "dependencies", "prefixItems", "contains", "unevaluatedProperties", "unevaluatedItems", "$dynamicRef", "$recursiveRef"}
+
+//JSONSchemaImportAdapter imports standard JSON Schema documents (draft-07
+//and 2020-12). JSONSchemaAdapter, by contrast, reads levo's own schema
+//format. Object schemas in $defs or definitions become models, as does the
+//root schema when it has properties, named after its title. allOf with a
+//$ref sets the parent, enum becomes an Enum, properties that are not
+//required are Optional and those that may be null are Nullable.
+type JSONSchemaImportAdapter struct{}
+
+func (self *JSONSchemaImportAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	fileContents, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.ParseJSONSchema(fileContents)
+}
+
+func (self *JSONSchemaImportAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	return self.ParseJSONSchema([]byte(schemaString))
+}
+
+//ParseJSONSchema lists constructs that have no levo equivalent in the
+//Unsupported field of the Schema built from the rest of the document.
+func (self *JSONSchemaImportAdapter) ParseJSONSchema(schemaJSON []byte) (Schema, error) {
+	document, err := decodeDocumentJSON(schemaJSON)
+	if err != nil {
+		return Schema{}, err
+	}
+	root, ok := document.(*documentObject)
+	if !ok {
+		return Schema{}, errors.New("JSON Schema document must be an object")
+	}
+
+	importer := newJSONSchemaImporter()
+	importer.addDefinitions(root.Object("definitions"), "#/definitions/")
+	importer.addDefinitions(root.Object("$defs"), "#/$defs/")
+	importer.Schema.Project = root.String("title")
+	if root.Has("properties") || root.Has("allOf") {
+		importer.RootName = Titlecase(root.String("title"))
+		if importer.RootName == "" {
+			importer.RootName = "Root"
+		}
+		importer.importModel(importer.RootName, root, "#")
+	}
+	importer.importDefinitions()
+	return importer.finish()
+}
+
+//jsonSchemaImporter converts JSON Schema objects to models. It is shared
+//by the importers of formats built on JSON Schema, such as OpenAPI.
+type jsonSchemaImporter struct {
+	Schema      Schema
+	RootName    string
+	Names       []string
+	Definitions map[string]*documentObject
+	Paths       map[string]string
+	Prefixes    []string
+	reported    map[string]bool
+	resolving   map[string]bool
+}
+
+func newJSONSchemaImporter() *jsonSchemaImporter {
+	return &jsonSchemaImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, Names: make([]string, 0), Definitions: make(map[string]*documentObject), Paths: make(map[string]string), Prefixes: make([]string, 0), reported: make(map[string]bool), resolving: make(map[string]bool)}
+}
+
+//addDefinitions registers the named schemas of definitions, which $refs
+//starting with prefix point to.
+func (self *jsonSchemaImporter) addDefinitions(definitions *documentObject, prefix string) {
+	self.Prefixes = append(self.Prefixes, prefix)
+	if definitions == nil {
+		return
+	}
+	for _, name := range definitions.Keys {
+		path := prefix + escapeJSONPointer(name)
+		definition, ok := definitions.Values[name].(*documentObject)
+		if !ok {
+			self.unsupported(path, "schema", "only object schemas can be imported")
+			continue
+		}
+		if _, ok := self.Definitions[name]; ok {
+			self.unsupported(path, "schema", "a definition with this name already exists")
+			continue
+		}
+		self.Names = append(self.Names, name)
+		self.Definitions[name] = definition
+		self.Paths[name] = path
+	}
+}
+
+func (self *jsonSchemaImporter) importDefinitions() {
+	for _, name := range self.Names {
+		definition := self.Definitions[name]
+		if definition.Has("enum") {
+			self.importEnum(name, definition, self.Paths[name])
+		} else if isObjectSchema(definition) {
+			self.importModel(name, definition, self.Paths[name])
+		}
+		//Anything else is an alias for a simple type, resolved wherever it
+		//is referenced.
+	}
+}
+
+func (self *jsonSchemaImporter) finish() (Schema, error) {
+	if err := self.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return self.Schema, nil
+}
+
+func (self *jsonSchemaImporter) importModel(name string, node *documentObject, path string) string {
+	//Reserve the model's place so that it comes before any models nested
+	//in it.
+	index := len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, Model{Name: name})
+	model := Model{Name: name, Properties: make([]ModelProperty, 0)}
+
+	required := make(map[string]bool)
+	parts := []*documentObject{node}
+	partPaths := []string{path}
+	allOf, _ := node.Get("allOf").([]interface{})
+	for partIndex, part := range allOf {
+		partPath := path + "/allOf/" + strconv.Itoa(partIndex)
+		partNode, ok := part.(*documentObject)
+		if !ok {
+			self.unsupported(partPath, "schema", "only object schemas can be imported")
+			continue
+		}
+		if ref := partNode.String("$ref"); ref != "" {
+			parentName, ok := self.refName(ref, partPath)
+			if !ok {
+				continue
+			}
+			if model.Parent != "" {
+				self.unsupported(partPath, "allOf", "multiple inheritance; only the first $ref becomes the parent")
+				continue
+			}
+			model.Parent = parentName
+			continue
+		}
+		parts = append(parts, partNode)
+		partPaths = append(partPaths, partPath)
+	}
+
+	for _, part := range parts {
+		for _, propertyName := range part.Strings("required") {
+			required[propertyName] = true
+		}
+	}
+	for partIndex, part := range parts {
+		partPath := partPaths[partIndex]
+		self.checkKeywords(part, partPath)
+		for _, keyword := range []string{"oneOf", "anyOf"} {
+			if part.Has(keyword) {
+				self.unsupported(partPath+"/"+keyword, keyword, "models can't be unions")
+			}
+		}
+		if additionalProperties, ok := part.Get("additionalProperties").(*documentObject); ok && len(additionalProperties.Keys) > 0 {
+			self.unsupported(partPath+"/additionalProperties", "additionalProperties", "models can't have arbitrary keys")
+		}
+		properties := part.Object("properties")
+		if properties == nil {
+			continue
+		}
+		for _, propertyName := range properties.Keys {
+			propertyPath := partPath + "/properties/" + escapeJSONPointer(propertyName)
+			propertyNode, ok := properties.Values[propertyName].(*documentObject)
+			if !ok {
+				self.unsupported(propertyPath, "schema", "only object schemas can be imported")
+				continue
+			}
+			if modelHasProperty(model, propertyName) {
+				continue
+			}
+			propertyType, isSetType, nullable, ok := self.resolveType(name+Titlecase(propertyName), propertyNode, propertyPath)
+			if !ok {
+				continue
+			}
+			property := ModelProperty{RemoteIdentifier: propertyName, LocalIdentifier: propertyName, PropertyType: propertyType, IsSetType: isSetType, Optional: !required[propertyName], Nullable: nullable}
+			model.Properties = append(model.Properties, property)
+		}
+	}
+
+	self.Schema.Models[index] = model
+	return name
+}
+
+//resolveType works out the levo type of a schema used as a property type.
+//Inline objects and enums are imported under inlineName.
+func (self *jsonSchemaImporter) resolveType(inlineName string, node *documentObject, path string) (string, bool, bool, bool) {
+	if ref := node.String("$ref"); ref != "" {
+		return self.resolveRef(ref, path)
+	}
+	self.checkKeywords(node, path)
+
+	//A lone allOf entry is the usual way to annotate a $ref
+	if allOf, _ := node.Get("allOf").([]interface{}); len(allOf) == 1 && !node.Has("properties") {
+		if part, ok := allOf[0].(*documentObject); ok {
+			return self.resolveType(inlineName, part, path+"/allOf/0")
+		}
+	}
+
+	//A union of one type and null is how optional values are usually
+	//written; any other union is unsupported.
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		alternatives, ok := node.Get(keyword).([]interface{})
+		if !ok {
+			continue
+		}
+		var alternative *documentObject
+		alternativeIndex := 0
+		count := 0
+		for index, value := range alternatives {
+			alternativeNode, ok := value.(*documentObject)
+			if ok && alternativeNode.String("type") == "null" {
+				continue
+			}
+			alternative = alternativeNode
+			alternativeIndex = index
+			count++
+		}
+		if count != 1 || alternative == nil {
+			self.unsupported(path+"/"+keyword, keyword, "union types")
+			return "", false, false, false
+		}
+		propertyType, isSetType, _, ok := self.resolveType(inlineName, alternative, path+"/"+keyword+"/"+strconv.Itoa(alternativeIndex))
+		return propertyType, isSetType, len(alternatives) > 1, ok
+	}
+
+	types, nullable := schemaTypes(node)
+	if node.Has("enum") {
+		return self.importEnum(self.availableName(inlineName), node, path), false, nullable || enumAllowsNull(node), true
+	}
+	if len(types) == 0 && isObjectSchema(node) {
+		types = []string{"object"}
+	}
+	if len(types) == 0 {
+		self.unsupported(path, "type", "schemas without a type")
+		return "", false, false, false
+	}
+	if len(types) > 1 {
+		self.unsupported(path+"/type", "type", "values with more than one type")
+		return "", false, false, false
+	}
+
+	switch types[0] {
+	case "array":
+		if _, ok := node.Get("items").([]interface{}); ok {
+			self.unsupported(path+"/items", "items", "tuples")
+			return "", false, false, false
+		}
+		items := node.Object("items")
+		if items == nil {
+			self.unsupported(path, "items", "arrays without an item schema")
+			return "", false, false, false
+		}
+		itemType, itemIsSetType, _, ok := self.resolveType(inflect.Singularize(inlineName), items, path+"/items")
+		if ok && itemIsSetType {
+			self.unsupported(path+"/items", "items", "nested arrays")
+			return "", false, false, false
+		}
+		return itemType, true, nullable, ok
+	case "object":
+		if !node.Has("properties") && !node.Has("allOf") {
+			self.unsupported(path, "type", "objects without properties")
+			return "", false, false, false
+		}
+		return self.importModel(self.availableName(inlineName), node, path), false, nullable, true
+	case "string":
+		format := node.String("format")
+		if format == "date" || format == "date-time" {
+			return "date", false, nullable, true
+		}
+		return "string", false, nullable, true
+	case "integer":
+		if node.String("format") == "int64" {
+			return "long", false, nullable, true
+		}
+		return "int", false, nullable, true
+	case "number":
+		return "float", false, nullable, true
+	case "boolean":
+		return "boolean", false, nullable, true
+	}
+	self.unsupported(path+"/type", "type", "type "+types[0])
+	return "", false, false, false
+}
+
+func (self *jsonSchemaImporter) resolveRef(ref string, path string) (string, bool, bool, bool) {
+	name, ok := self.refName(ref, path+"/$ref")
+	if !ok {
+		return "", false, false, false
+	}
+	definition, ok := self.Definitions[name]
+	if !ok || definition.Has("enum") || isObjectSchema(definition) {
+		//A model or enum, including the root model
+		return name, false, false, true
+	}
+	if self.resolving[name] {
+		self.unsupported(path+"/$ref", "$ref", "circular references between simple types")
+		return "", false, false, false
+	}
+	self.resolving[name] = true
+	defer delete(self.resolving, name)
+	return self.resolveType(name, definition, self.Paths[name])
+}
+
+//refName returns the name of the definition ref points to.
+func (self *jsonSchemaImporter) refName(ref string, path string) (string, bool) {
+	if ref == "#" && self.RootName != "" {
+		return self.RootName, true
+	}
+	for _, prefix := range self.Prefixes {
+		if !strings.HasPrefix(ref, prefix) || strings.Contains(ref[len(prefix):], "/") {
+			continue
+		}
+		name := unescapeJSONPointer(ref[len(prefix):])
+		if _, ok := self.Definitions[name]; ok {
+			return name, true
+		}
+	}
+	self.unsupported(path, "$ref", "only references to local definitions are supported, not "+ref)
+	return "", false
+}
+
+func (self *jsonSchemaImporter) importEnum(name string, node *documentObject, path string) string {
+	enum := Enum{Name: name, Values: make([]EnumValue, 0)}
+	values, _ := node.Get("enum").([]interface{})
+	for index, value := range values {
+		valueType := ""
+		switch typedValue := value.(type) {
+		case nil:
+			//null only makes the property optional
+			continue
+		case string:
+			valueType = "string"
+		case json.Number:
+			valueType = "int"
+			if _, err := typedValue.Int64(); err != nil {
+				valueType = "float"
+			}
+		case int, int64:
+			valueType = "int"
+		case float64:
+			valueType = "float"
+		default:
+			self.unsupported(path+"/enum/"+strconv.Itoa(index), "enum", "enum values must be strings or numbers")
+			continue
+		}
+		if enum.Type == "" {
+			enum.Type = valueType
+		} else if enum.Type != valueType {
+			self.unsupported(path+"/enum/"+strconv.Itoa(index), "enum", "enum values of mixed types")
+			continue
+		}
+		rawValue, _ := documentScalarString(value)
+		enum.Values = append(enum.Values, EnumValue{Name: rawValue, RawValue: rawValue})
+	}
+	if enum.Type == "" {
+		enum.Type = "string"
+	}
+	self.Schema.Enums = append(self.Schema.Enums, enum)
+	return name
+}
+
+func (self *jsonSchemaImporter) checkKeywords(node *documentObject, path string) {
+	for _, keyword := range unsupportedJSONSchemaKeywords {
+		if node.Has(keyword) {
+			self.unsupported(path+"/"+keyword, keyword, "no levo equivalent")
+		}
+	}
+}
+
+func (self *jsonSchemaImporter) unsupported(path string, keyword string, reason string) {
+	//Aliases are resolved every time they're referenced; report them once
+	if self.reported[path+" "+keyword] {
+		return
+	}
+	self.reported[path+" "+keyword] = true
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+//availableName returns name, or name with a number added if a model, enum
+//or definition already uses it.
+func (self *jsonSchemaImporter) availableName(name string) string {
+	candidate := name
+	for suffix := 2; self.nameTaken(candidate); suffix++ {
+		candidate = name + strconv.Itoa(suffix)
+	}
+	return candidate
+}
+
+func (self *jsonSchemaImporter) nameTaken(name string) bool {
+	if _, ok := self.Definitions[name]; ok || name == self.RootName {
+		return true
+	}
+	for _, model := range self.Schema.Models {
+		if model.Name == name {
+			return true
+		}
+	}
+	for _, enum := range self.Schema.Enums {
+		if enum.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func isObjectSchema(node *documentObject) bool {
+	types, _ := schemaTypes(node)
+	for _, schemaType := range types {
+		if schemaType == "object" {
+			return true
+		}
+	}
+	return len(types) == 0 && (node.Has("properties") || node.Has("allOf"))
+}
+
+//schemaTypes returns the types a schema allows other than null, and
+//whether it allows null, from either form of the type keyword or OpenAPI's
+//nullable.
+func schemaTypes(node *documentObject) ([]string, bool) {
+	types := make([]string, 0)
+	nullable := node.Get("nullable") == true
+	declared := make([]string, 0)
+	switch typeValue := node.Get("type").(type) {
+	case string:
+		declared = append(declared, typeValue)
+	case []interface{}:
+		for _, value := range typeValue {
+			if typeName, ok := value.(string); ok {
+				declared = append(declared, typeName)
+			}
+		}
+	}
+	for _, typeName := range declared {
+		if typeName == "null" {
+			nullable = true
+		} else {
+			types = append(types, typeName)
+		}
+	}
+	return types, nullable
+}
+
+func enumAllowsNull(node *documentObject) bool {
+	values, _ := node.Get("enum").([]interface{})
+	for _, value := range values {
+		if value == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func modelHasProperty(model Model, remoteIdentifier string) bool {
+	for _, property := range model.Properties {
+		if property.RemoteIdentifier == remoteIdentifier {
+			return true
+		}
+	}
+	return false
+}
+
+func escapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~", "~0", -1), "/", "~1", -1)
+}
+
+func unescapeJSONPointer(token string) string {
+	return strings.Replace(strings.Replace(token, "~1", "/", -1), "~0", "~", -1)
+}
@@ -0,0 +1,129 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportJSONSchemaFile(testing *testing.T) {
+	adapter := GetJSONSchemaImportAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/json-schema.json")
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if schema.Project != "Pet Store" {
+		testing.Errorf("Expecting project %v. Got %v", "Pet Store", schema.Project)
+	}
+
+	expectedModels := []Model{
+		{Name: "Animal", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"},
+			{RemoteIdentifier: "born", LocalIdentifier: "born", PropertyType: "date", Optional: true},
+		}},
+		{Name: "Pet", Parent: "Animal", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long"},
+			{RemoteIdentifier: "status", LocalIdentifier: "status", PropertyType: "Status"},
+			{RemoteIdentifier: "weight", LocalIdentifier: "weight", PropertyType: "float", Nullable: true},
+			{RemoteIdentifier: "tags", LocalIdentifier: "tags", PropertyType: "PetTag", IsSetType: true},
+			{RemoteIdentifier: "size", LocalIdentifier: "size", PropertyType: "PetSize", Optional: true},
+		}},
+		{Name: "PetTag", Properties: []ModelProperty{
+			{RemoteIdentifier: "label", LocalIdentifier: "label", PropertyType: "string", Optional: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	expectedEnums := []Enum{
+		{Name: "Status", Type: "string", Values: []EnumValue{{Name: "available", RawValue: "available"}, {Name: "sold", RawValue: "sold"}}},
+		{Name: "PetSize", Type: "int", Values: []EnumValue{{Name: "1", RawValue: "1"}, {Name: "2", RawValue: "2"}, {Name: "3", RawValue: "3"}}},
+	}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting enums %v. Got %v", expectedEnums, schema.Enums)
+	}
+}
+
+func TestImportJSONSchemaRootAndDefinitions(testing *testing.T) {
+	adapter := GetJSONSchemaImportAdapter()
+	schema, err := adapter.ProcessSchemaString(`{
+		"title": "order",
+		"type": "object",
+		"properties": {
+			"total": {"type": "number"},
+			"customer": {"$ref": "#/definitions/Customer"},
+			"parent": {"anyOf": [{"$ref": "#"}, {"type": "null"}]}
+		},
+		"required": ["total", "customer"],
+		"definitions": {
+			"Customer": {"type": "object", "properties": {"email": {"type": "string"}}}
+		}
+	}`)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(schema.Models) != 2 || schema.Models[0].Name != "Order" || schema.Models[1].Name != "Customer" {
+		testing.Fatalf("Expecting models Order and Customer. Got %v", schema.Models)
+	}
+	expectedProperties := []ModelProperty{
+		{RemoteIdentifier: "total", LocalIdentifier: "total", PropertyType: "float"},
+		{RemoteIdentifier: "customer", LocalIdentifier: "customer", PropertyType: "Customer"},
+		{RemoteIdentifier: "parent", LocalIdentifier: "parent", PropertyType: "Order", Optional: true, Nullable: true},
+	}
+	if !reflect.DeepEqual(schema.Models[0].Properties, expectedProperties) {
+		testing.Errorf("Expecting properties %v. Got %v", expectedProperties, schema.Models[0].Properties)
+	}
+}
+
+func TestImportJSONSchemaUnsupported(testing *testing.T) {
+	adapter := GetJSONSchemaImportAdapter()
+	schema, err := adapter.ProcessSchemaString(`{
+		"$defs": {
+			"Shape": {
+				"type": "object",
+				"properties": {
+					"name": {"type": "string"},
+					"kind": {"oneOf": [{"type": "string"}, {"type": "integer"}]},
+					"extra": {"type": "object"},
+					"remote": {"$ref": "https://example.com/remote.json"}
+				},
+				"patternProperties": {"^x-": {"type": "string"}}
+			}
+		}
+	}`)
+	if err != nil {
+		testing.Fatalf("Expecting the unsupported constructs to be listed without an error. Got %v", err)
+	}
+	expectedPaths := []string{"#/$defs/Shape/patternProperties", "#/$defs/Shape/properties/kind/oneOf", "#/$defs/Shape/properties/extra", "#/$defs/Shape/properties/remote/$ref"}
+	paths := make([]string, 0)
+	for _, construct := range schema.Unsupported {
+		paths = append(paths, construct.Path)
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		testing.Errorf("Expecting unsupported constructs at %v. Got %v", expectedPaths, paths)
+	}
+
+	//The supported parts are still imported
+	if len(schema.Models) != 1 || len(schema.Models[0].Properties) != 1 || schema.Models[0].Properties[0].RemoteIdentifier != "name" {
+		testing.Errorf("Expecting model Shape with property name. Got %v", schema.Models)
+	}
+
+	_, err = adapter.ProcessSchemaString(`["not", "a", "schema"]`)
+	if err == nil {
+		testing.Errorf("Importing a JSON array did not fail")
+	}
+}
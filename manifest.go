@@ -0,0 +1,128 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+//The manifest lives in the root of the output directory and records what
+//the last generation run produced.
+const ManifestFileName string = ".levo-manifest.json"
+
+type Manifest struct {
+	LibraryVersion  string
+	TemplateSetHash string
+	Entries         []ManifestEntry
+}
+
+//A ManifestEntry describes one execution of a template against the models
+//of a mapping.
+type ManifestEntry struct {
+	TemplatePath string
+	TemplateHash string
+	ModelHash    string
+	Files        []ManifestFile
+}
+
+type ManifestFile struct {
+	//Path is relative to the output directory and uses forward slashes
+	Path string
+	Hash string
+}
+
+func ReadManifest(outputDirectory string) (Manifest, error) {
+	fileContents, err := ioutil.ReadFile(filepath.Join(outputDirectory, ManifestFileName))
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	} else if err != nil {
+		return Manifest{}, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(fileContents, &manifest); err != nil {
+		return Manifest{}, err
+	}
+	return manifest, nil
+}
+
+func WriteManifest(outputDirectory string, manifest Manifest) error {
+	fileContents, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(outputDirectory, DefaultDirectoryMode); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(outputDirectory, ManifestFileName), append(fileContents, '\n'), DefaultFileMode)
+}
+
+//Files returns every path recorded in the manifest, sorted and without
+//duplicates.
+func (self *Manifest) Files() []string {
+	pathSet := make(map[string]bool)
+	for _, entry := range self.Entries {
+		for _, file := range entry.Files {
+			pathSet[file.Path] = true
+		}
+	}
+	paths := make([]string, 0, len(pathSet))
+	for path := range pathSet {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (self *ManifestEntry) key() string {
+	return self.TemplatePath + "@" + self.ModelHash
+}
+
+func hashBytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func templatePath(templateInfo *TemplateInfo) string {
+	return filepath.ToSlash(filepath.Join(templateInfo.Directory, templateInfo.FileName))
+}
+
+//Go templates are parsed into one associated set and can include each
+//other, so a change to any template may change the output of all of them.
+//So may a change to the custom type mappings they all share.
+func templateSetHash(context Context) string {
+	hashes := make([]string, 0, len(context.Templates))
+	for index := range context.Templates {
+		templateInfo := &context.Templates[index]
+		hashes = append(hashes, templatePath(templateInfo)+":"+hashBytes(templateInfo.Body))
+	}
+	sort.Strings(hashes)
+	encodedHashes, _ := json.Marshal(hashes)
+	encodedTypes, _ := json.Marshal(context.TypeRegistry.mappings())
+	return hashBytes(append(encodedHashes, encodedTypes...))
+}
+
+func templateDataHash(templateData TemplateData) (string, error) {
+	encodedData, err := json.Marshal(templateData)
+	if err != nil {
+		return "", err
+	}
+	return hashBytes(encodedData), nil
+}
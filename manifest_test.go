@@ -0,0 +1,54 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestReadWriteManifest(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+
+	//A missing manifest is an empty manifest
+	manifest, err := ReadManifest(outputDirectory)
+	if err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if len(manifest.Entries) != 0 {
+		testing.Errorf("Expecting an empty manifest. Got %v", manifest)
+	}
+
+	manifest = Manifest{LibraryVersion: LibraryVersion, TemplateSetHash: "abc", Entries: []ManifestEntry{
+		ManifestEntry{TemplatePath: "b.lt", Files: []ManifestFile{ManifestFile{Path: "src/B.java", Hash: "1"}, ManifestFile{Path: "src/A.java", Hash: "2"}}},
+		ManifestEntry{TemplatePath: "a.lt", Files: []ManifestFile{ManifestFile{Path: "src/A.java", Hash: "3"}}},
+	}}
+	if err = WriteManifest(outputDirectory, manifest); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	readManifest, err := ReadManifest(outputDirectory)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if !reflect.DeepEqual(manifest, readManifest) {
+		testing.Errorf("Expecting manifest %v. Got %v", manifest, readManifest)
+	}
+
+	expectedFiles := []string{"src/A.java", "src/B.java"}
+	if files := readManifest.Files(); !reflect.DeepEqual(files, expectedFiles) {
+		testing.Errorf("Expecting files %v. Got %v", expectedFiles, files)
+	}
+}
@@ -0,0 +1,97 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+//OpenAPIAdapter imports the schemas in the components section of an
+//OpenAPI 3 document, in YAML or JSON. Each object schema becomes a model;
+//the schemas are converted as JSONSchemaImportAdapter converts $defs, so an
+//allOf with a $ref sets the model's Parent and enums become Enums.
+type OpenAPIAdapter struct{}
+
+//ProcessSchemaFile reads files ending in .json as JSON and anything else as
+//YAML.
+func (self *OpenAPIAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	fileContents, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	if strings.ToLower(filepath.Ext(schemaPath)) == ".json" {
+		return self.ParseOpenAPIJSON(fileContents)
+	}
+	return self.ParseOpenAPIYAML(fileContents)
+}
+
+//ProcessSchemaString accepts either YAML or JSON.
+func (self *OpenAPIAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	if strings.HasPrefix(strings.TrimSpace(schemaString), "{") {
+		return self.ParseOpenAPIJSON([]byte(schemaString))
+	}
+	return self.ParseOpenAPIYAML([]byte(schemaString))
+}
+
+func (self *OpenAPIAdapter) ParseOpenAPIJSON(specification []byte) (Schema, error) {
+	document, err := decodeDocumentJSON(specification)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.importDocument(document)
+}
+
+func (self *OpenAPIAdapter) ParseOpenAPIYAML(specification []byte) (Schema, error) {
+	document, err := decodeDocumentYAML(specification)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.importDocument(document)
+}
+
+//importDocument lists constructs that have no levo equivalent in the
+//Unsupported field of the Schema built from the rest of the document.
+func (self *OpenAPIAdapter) importDocument(document interface{}) (Schema, error) {
+	root, ok := document.(*documentObject)
+	if !ok {
+		return Schema{}, errors.New("OpenAPI document must be an object")
+	}
+	version := openAPIVersion(root.Get("openapi"))
+	if !strings.HasPrefix(version, "3.") {
+		return Schema{}, errors.New("Only OpenAPI 3 documents are supported. Found version: " + version)
+	}
+
+	importer := newJSONSchemaImporter()
+	importer.Schema.Project = root.Object("info").String("title")
+	importer.addDefinitions(root.Object("components").Object("schemas"), "#/components/schemas/")
+	importer.importDefinitions()
+	return importer.finish()
+}
+
+//openAPIVersion formats the openapi field of a document. An unquoted YAML
+//version such as 3.0 decodes as a number, so integral numbers keep a
+//trailing .0.
+func openAPIVersion(value interface{}) string {
+	if number, ok := value.(float64); ok && number == math.Trunc(number) {
+		return strconv.FormatFloat(number, 'f', 1, 64)
+	}
+	version, _ := documentScalarString(value)
+	return version
+}
@@ -0,0 +1,91 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProcessOpenAPIFile(testing *testing.T) {
+	adapter := GetOpenAPIAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/openapi.yaml")
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if schema.Project != "Library" {
+		testing.Errorf("Expecting project %v. Got %v", "Library", schema.Project)
+	}
+
+	expectedModels := []Model{
+		{Name: "Item", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long"},
+			{RemoteIdentifier: "title", LocalIdentifier: "title", PropertyType: "string", Optional: true},
+		}},
+		{Name: "Book", Parent: "Item", Properties: []ModelProperty{
+			{RemoteIdentifier: "published", LocalIdentifier: "published", PropertyType: "date", Optional: true},
+			{RemoteIdentifier: "authors", LocalIdentifier: "authors", PropertyType: "Author", IsSetType: true, Optional: true},
+			{RemoteIdentifier: "rating", LocalIdentifier: "rating", PropertyType: "float", Optional: true, Nullable: true},
+		}},
+		{Name: "Author", Properties: []ModelProperty{
+			{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string", Optional: true},
+			{RemoteIdentifier: "genre", LocalIdentifier: "genre", PropertyType: "AuthorGenre", Optional: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+	if len(schema.Enums) != 1 || schema.Enums[0].Name != "AuthorGenre" || len(schema.Enums[0].Values) != 2 {
+		testing.Errorf("Expecting enum AuthorGenre with 2 values. Got %v", schema.Enums)
+	}
+}
+
+func TestProcessOpenAPIString(testing *testing.T) {
+	adapter := GetOpenAPIAdapter()
+	schema, err := adapter.ProcessSchemaString(`{
+		"openapi": "3.1.0",
+		"info": {"title": "Pets"},
+		"components": {"schemas": {
+			"Pet": {"type": "object", "properties": {
+				"name": {"type": "string"},
+				"owner": {"$ref": "#/components/responses/Owner"}
+			}}
+		}}
+	}`)
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Keyword != "$ref" {
+		testing.Errorf("Expecting the reference to a response to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) != 1 || len(schema.Models[0].Properties) != 1 {
+		testing.Errorf("Expecting model Pet with one property. Got %v", schema.Models)
+	}
+
+	for _, version := range []string{"3.0", "3.1", "\"3.0.3\""} {
+		schema, err = adapter.ProcessSchemaString("openapi: " + version + "\ncomponents:\n  schemas:\n    Pet:\n      type: object\n")
+		if err != nil || len(schema.Models) != 1 {
+			testing.Errorf("Expecting openapi: %v to import model Pet. Got %v, %v", version, schema.Models, err)
+		}
+	}
+
+	_, err = adapter.ProcessSchemaString("openapi: 2.0\ninfo:\n  title: Old\n")
+	if err == nil || !strings.Contains(err.Error(), "2.0") {
+		testing.Errorf("Expecting openapi: 2.0 to be rejected. Got %v", err)
+	}
+
+	_, err = adapter.ProcessSchemaString("swagger: \"2.0\"\ninfo:\n  title: Old\n")
+	if err == nil {
+		testing.Errorf("Importing a Swagger 2 document did not fail")
+	}
+}
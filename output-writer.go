@@ -0,0 +1,197 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const DefaultFileMode os.FileMode = 0644
+const DefaultDirectoryMode os.FileMode = 0755
+
+type WriteStatus int
+
+const (
+	FileCreated WriteStatus = iota
+	FileOverwritten
+	FileUnchanged
+	FileRemoved
+)
+
+func (self WriteStatus) String() string {
+	switch self {
+	case FileCreated:
+		return "created"
+	case FileOverwritten:
+		return "overwritten"
+	case FileUnchanged:
+		return "unchanged"
+	case FileRemoved:
+		return "removed"
+	}
+	return "unknown"
+}
+
+type WrittenFile struct {
+	Path   string
+	Status WriteStatus
+}
+
+type OutputWriter interface {
+	WriteFile(file GeneratedFile) (WrittenFile, error)
+}
+
+type FileSystemWriter struct {
+	OutputDirectory          string
+	DirectoryMode            os.FileMode
+	PreserveProtectedRegions bool
+	CleanStaleFiles          bool
+}
+
+func GetFileSystemWriter(outputDirectory string) FileSystemWriter {
+	return FileSystemWriter{OutputDirectory: outputDirectory, DirectoryMode: DefaultDirectoryMode, PreserveProtectedRegions: true}
+}
+
+func WriteGeneratedFiles(writer OutputWriter, files []GeneratedFile) ([]WrittenFile, error) {
+	writtenFiles := make([]WrittenFile, 0, len(files))
+	for _, file := range files {
+		writtenFile, err := writer.WriteFile(file)
+		if err != nil {
+			return writtenFiles, err
+		}
+		writtenFiles = append(writtenFiles, writtenFile)
+	}
+	return writtenFiles, nil
+}
+
+//plannedFile describes what WriteFile would do with a GeneratedFile without
+//touching the disk. It is shared with the dry run in DiffGeneratedFiles.
+type plannedFile struct {
+	Path         string
+	Body         []byte
+	Mode         os.FileMode
+	Status       WriteStatus
+	ExistingBody []byte
+	ExistingMode os.FileMode
+}
+
+func (self *FileSystemWriter) WriteFile(file GeneratedFile) (WrittenFile, error) {
+	plan, err := self.planFile(file)
+	if err != nil {
+		return WrittenFile{}, err
+	}
+	return self.writePlannedFile(plan)
+}
+
+func (self *FileSystemWriter) writePlannedFile(plan plannedFile) (WrittenFile, error) {
+	if plan.Status == FileUnchanged {
+		return WrittenFile{Path: plan.Path, Status: FileUnchanged}, nil
+	}
+
+	directoryMode := self.DirectoryMode
+	if directoryMode == 0 {
+		directoryMode = DefaultDirectoryMode
+	}
+	if err := os.MkdirAll(filepath.Dir(plan.Path), directoryMode); err != nil {
+		return WrittenFile{}, err
+	}
+	if err := ioutil.WriteFile(plan.Path, plan.Body, plan.Mode); err != nil {
+		return WrittenFile{}, err
+	}
+	//WriteFile only applies the mode to new files
+	if err := os.Chmod(plan.Path, plan.Mode); err != nil {
+		return WrittenFile{}, err
+	}
+	return WrittenFile{Path: plan.Path, Status: plan.Status}, nil
+}
+
+func (self *FileSystemWriter) planFile(file GeneratedFile) (plannedFile, error) {
+	path, err := self.PathForFile(file)
+	if err != nil {
+		return plannedFile{}, err
+	}
+	plan := plannedFile{Path: path, Mode: file.Mode.Perm(), Status: FileCreated}
+	if plan.Mode == 0 {
+		plan.Mode = DefaultFileMode
+	}
+
+	if existingInfo, err := os.Stat(path); err == nil {
+		if existingInfo.IsDir() {
+			return plannedFile{}, errors.New("Cannot write file " + path + ", a directory exists at that path")
+		}
+		plan.ExistingBody, err = ioutil.ReadFile(path)
+		if err != nil {
+			return plannedFile{}, err
+		}
+		plan.ExistingMode = existingInfo.Mode().Perm()
+		plan.Status = FileOverwritten
+		if self.PreserveProtectedRegions {
+			if file, err = MergeProtectedRegions(file, plan.ExistingBody); err != nil {
+				return plannedFile{}, err
+			}
+		}
+	} else if !os.IsNotExist(err) {
+		return plannedFile{}, err
+	}
+
+	plan.Body, err = file.DecodedBody()
+	if err != nil {
+		return plannedFile{}, err
+	}
+	if plan.Status == FileOverwritten && bytes.Equal(plan.ExistingBody, plan.Body) && plan.ExistingMode == plan.Mode {
+		plan.Status = FileUnchanged
+	}
+	return plan, nil
+}
+
+func (self *FileSystemWriter) PathForFile(file GeneratedFile) (string, error) {
+	relativePath, err := file.RelativePath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(self.OutputDirectory, relativePath), nil
+}
+
+func (self GeneratedFile) RelativePath() (string, error) {
+	if self.FileName == "" {
+		return "", errors.New("Generated file must have a filename")
+	}
+	relativePath := filepath.Clean(filepath.Join(self.Directory, self.FileName))
+	if filepath.IsAbs(relativePath) || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", errors.New("Generated file " + self.FileName + " would be written outside of the output directory")
+	}
+	return relativePath, nil
+}
+
+//Static templates are stored base64 encoded (see Context.AddTemplate).
+//DecodedBody returns the bytes that belong on disk.
+func (self GeneratedFile) DecodedBody() ([]byte, error) {
+	if !bytes.HasPrefix(self.Body, []byte(base64BodyPrefix)) {
+		return self.Body, nil
+	}
+	encodedBody := self.Body[len(base64BodyPrefix):]
+	body := make([]byte, base64.StdEncoding.DecodedLen(len(encodedBody)))
+	length, err := base64.StdEncoding.Decode(body, encodedBody)
+	if err != nil {
+		return []byte{}, err
+	}
+	return body[:length], nil
+}
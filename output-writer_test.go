@@ -0,0 +1,119 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func SetupOutputDirectory(testing *testing.T) string {
+	outputDirectory, err := ioutil.TempDir("", "levo-output")
+	if err != nil {
+		testing.Fatalf("Unable to create output directory: %v", err.Error())
+	}
+	return outputDirectory
+}
+
+func TestFileSystemWriterWriteFile(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+
+	file := GeneratedFile{FileName: "first.txt", Directory: "levo_gen/subdir", Body: []byte("Contents")}
+	writtenFile, err := writer.WriteFile(file)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	expectedPath := filepath.Join(outputDirectory, "levo_gen", "subdir", "first.txt")
+	if writtenFile.Path != expectedPath {
+		testing.Errorf("Expecting path %v. Got %v", expectedPath, writtenFile.Path)
+	}
+	if writtenFile.Status != FileCreated {
+		testing.Errorf("Expecting status %v. Got %v", FileCreated, writtenFile.Status)
+	}
+	if contents, err := ioutil.ReadFile(expectedPath); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if string(contents) != "Contents" {
+		testing.Errorf("Expecting contents %v. Got %v", "Contents", string(contents))
+	}
+
+	//Writing the same file again should leave it alone
+	if writtenFile, err = writer.WriteFile(file); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if writtenFile.Status != FileUnchanged {
+		testing.Errorf("Expecting status %v. Got %v", FileUnchanged, writtenFile.Status)
+	}
+
+	//Changing the mode should overwrite it
+	file.Mode = 0755
+	if writtenFile, err = writer.WriteFile(file); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if writtenFile.Status != FileOverwritten {
+		testing.Errorf("Expecting status %v. Got %v", FileOverwritten, writtenFile.Status)
+	}
+	if info, err := os.Stat(expectedPath); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if info.Mode().Perm() != 0755 {
+		testing.Errorf("Expecting mode %v. Got %v", os.FileMode(0755), info.Mode().Perm())
+	}
+
+	//Changing the body should overwrite it
+	file.Body = []byte("New Contents")
+	if writtenFile, err = writer.WriteFile(file); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if writtenFile.Status != FileOverwritten {
+		testing.Errorf("Expecting status %v. Got %v", FileOverwritten, writtenFile.Status)
+	}
+
+	//Files must stay inside the output directory
+	if _, err = writer.WriteFile(GeneratedFile{FileName: "escape.txt", Directory: "../.."}); err == nil {
+		testing.Errorf("No error returned when writing outside of the output directory")
+	}
+	if _, err = writer.WriteFile(GeneratedFile{Directory: "levo_gen"}); err == nil {
+		testing.Errorf("No error returned when writing a file without a filename")
+	}
+}
+
+func TestWriteGeneratedFiles(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+
+	SetupContext()
+	SetupTemplate()
+	SetupModel()
+	SetupMapping()
+	generatedFiles, err := ProcessMappings(context)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+
+	writtenFiles, err := WriteGeneratedFiles(&writer, generatedFiles)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(writtenFiles) != len(generatedFiles) {
+		testing.Fatalf("Expecting %v written files. Got %v", len(generatedFiles), len(writtenFiles))
+	}
+	//Static templates should be written decoded
+	if contents, err := ioutil.ReadFile(writtenFiles[1].Path); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if string(contents) != "Lorem ipsum dolor sit amet, consectetur adipiscing elit." {
+		testing.Errorf("Static template was not decoded. Got %v", string(contents))
+	}
+}
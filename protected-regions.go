@@ -0,0 +1,125 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"strconv"
+)
+
+//Protected regions are delimited by lines containing the markers below,
+//usually inside a comment, e.g.
+//
+//	// levo:begin custom
+//	hand written code
+//	// levo:end
+//
+//Only the marker text matters so any comment syntax will do.
+var protectedRegionBeginRegex *regexp.Regexp = regexp.MustCompile(`levo:begin[ \t]+([^ \t\r\n]+)`)
+var protectedRegionEndRegex *regexp.Regexp = regexp.MustCompile(`levo:end\b`)
+
+type protectedRegion struct {
+	Name      string
+	BeginLine int
+	EndLine   int
+}
+
+func MergeProtectedRegions(generatedFile GeneratedFile, existingBody []byte) (GeneratedFile, error) {
+	if bytes.HasPrefix(generatedFile.Body, []byte(base64BodyPrefix)) {
+		//Static files are copied as is
+		return generatedFile, nil
+	}
+	existingLines := splitLines(existingBody)
+	existingRegions, err := findProtectedRegions(existingLines)
+	if err != nil {
+		return GeneratedFile{}, errors.New("Existing file " + generatedFile.FileName + ": " + err.Error())
+	}
+	if len(existingRegions) == 0 {
+		return generatedFile, nil
+	}
+	generatedLines := splitLines(generatedFile.Body)
+	generatedRegions, err := findProtectedRegions(generatedLines)
+	if err != nil {
+		return GeneratedFile{}, errors.New("Generated file " + generatedFile.FileName + ": " + err.Error())
+	}
+
+	existingByName := make(map[string]protectedRegion)
+	for _, region := range existingRegions {
+		existingByName[region.Name] = region
+	}
+	generatedByName := make(map[string]bool)
+	for _, region := range generatedRegions {
+		generatedByName[region.Name] = true
+	}
+	for _, region := range existingRegions {
+		if !generatedByName[region.Name] {
+			return GeneratedFile{}, errors.New("Protected region " + region.Name + " in " + generatedFile.FileName + " is no longer produced by its template")
+		}
+	}
+
+	mergedLines := make([][]byte, 0, len(generatedLines))
+	previousLine := 0
+	for _, region := range generatedRegions {
+		mergedLines = append(mergedLines, generatedLines[previousLine:region.BeginLine+1]...)
+		if existingRegion, ok := existingByName[region.Name]; ok {
+			mergedLines = append(mergedLines, existingLines[existingRegion.BeginLine+1:existingRegion.EndLine]...)
+		} else {
+			mergedLines = append(mergedLines, generatedLines[region.BeginLine+1:region.EndLine]...)
+		}
+		previousLine = region.EndLine
+	}
+	mergedLines = append(mergedLines, generatedLines[previousLine:]...)
+
+	generatedFile.Body = bytes.Join(mergedLines, []byte{})
+	return generatedFile, nil
+}
+
+//splitLines keeps the line endings so that joining the lines gives back
+//exactly the original body.
+func splitLines(body []byte) [][]byte {
+	return bytes.SplitAfter(body, []byte("\n"))
+}
+
+func findProtectedRegions(lines [][]byte) ([]protectedRegion, error) {
+	regions := make([]protectedRegion, 0)
+	names := make(map[string]bool)
+	var openRegion *protectedRegion
+	for index, line := range lines {
+		if match := protectedRegionBeginRegex.FindSubmatch(line); match != nil {
+			if openRegion != nil {
+				return []protectedRegion{}, errors.New("Protected region " + openRegion.Name + " is not closed before line " + strconv.Itoa(index+1))
+			}
+			name := string(match[1])
+			if names[name] {
+				return []protectedRegion{}, errors.New("Protected region " + name + " is declared more than once")
+			}
+			names[name] = true
+			openRegion = &protectedRegion{Name: name, BeginLine: index}
+		} else if protectedRegionEndRegex.Match(line) {
+			if openRegion == nil {
+				return []protectedRegion{}, errors.New("Unexpected end of protected region on line " + strconv.Itoa(index+1))
+			}
+			openRegion.EndLine = index
+			regions = append(regions, *openRegion)
+			openRegion = nil
+		}
+	}
+	if openRegion != nil {
+		return []protectedRegion{}, errors.New("Protected region " + openRegion.Name + " is not closed")
+	}
+	return regions, nil
+}
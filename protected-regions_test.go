@@ -0,0 +1,92 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+const protectedGeneratedBody string = "class Cat {\n\t// levo:begin fields\n\t// levo:end\n\tint lives;\n\t// levo:begin methods\n\tvoid meow() {}\n\t// levo:end\n}"
+const protectedExistingBody string = "class Cat {\n\t// levo:begin fields\n\tString nickname;\n\t// levo:end\n\tint legs;\n\t// levo:begin methods\n\tvoid purr() {}\n\t// levo:end\n}"
+const protectedMergedBody string = "class Cat {\n\t// levo:begin fields\n\tString nickname;\n\t// levo:end\n\tint lives;\n\t// levo:begin methods\n\tvoid purr() {}\n\t// levo:end\n}"
+
+func TestMergeProtectedRegions(testing *testing.T) {
+	generatedFile := GeneratedFile{FileName: "Cat.java", Body: []byte(protectedGeneratedBody)}
+
+	mergedFile, err := MergeProtectedRegions(generatedFile, []byte(protectedExistingBody))
+	if err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if string(mergedFile.Body) != protectedMergedBody {
+		testing.Errorf("Expecting body:\n%v\n\nGot:\n%v", protectedMergedBody, string(mergedFile.Body))
+	}
+
+	//Files without regions are left alone
+	if mergedFile, err = MergeProtectedRegions(generatedFile, []byte("class Cat {}")); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if string(mergedFile.Body) != protectedGeneratedBody {
+		testing.Errorf("Generated body was changed when the existing file had no regions")
+	}
+
+	//A region that the template no longer produces is an error
+	existingBody := "// levo:begin imports\nimport java.util.List;\n// levo:end\n" + protectedExistingBody
+	if _, err = MergeProtectedRegions(generatedFile, []byte(existingBody)); err == nil {
+		testing.Errorf("No error returned when a protected region disappeared from the template")
+	}
+
+	//Malformed regions are errors
+	if _, err = MergeProtectedRegions(generatedFile, []byte("// levo:begin fields\nint x;\n")); err == nil {
+		testing.Errorf("No error returned for an unclosed protected region")
+	}
+	if _, err = MergeProtectedRegions(generatedFile, []byte("int x;\n// levo:end\n")); err == nil {
+		testing.Errorf("No error returned for an unopened protected region")
+	}
+	duplicateBody := "// levo:begin fields\n// levo:end\n// levo:begin fields\n// levo:end\n"
+	if _, err = MergeProtectedRegions(generatedFile, []byte(duplicateBody)); err == nil {
+		testing.Errorf("No error returned for a duplicated protected region")
+	}
+}
+
+func TestFileSystemWriterPreservesProtectedRegions(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+
+	existingFile := GeneratedFile{FileName: "Cat.java", Directory: "src", Body: []byte(protectedExistingBody)}
+	if _, err := writer.WriteFile(existingFile); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+
+	writtenFile, err := writer.WriteFile(GeneratedFile{FileName: "Cat.java", Directory: "src", Body: []byte(protectedGeneratedBody)})
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if writtenFile.Status != FileOverwritten {
+		testing.Errorf("Expecting status %v. Got %v", FileOverwritten, writtenFile.Status)
+	}
+	if contents, err := ioutil.ReadFile(writtenFile.Path); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if string(contents) != protectedMergedBody {
+		testing.Errorf("Expecting body:\n%v\n\nGot:\n%v", protectedMergedBody, string(contents))
+	}
+
+	//Regenerating again should not change anything
+	if writtenFile, err = writer.WriteFile(GeneratedFile{FileName: "Cat.java", Directory: "src", Body: []byte(protectedGeneratedBody)}); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	} else if writtenFile.Status != FileUnchanged {
+		testing.Errorf("Expecting status %v. Got %v", FileUnchanged, writtenFile.Status)
+	}
+}
@@ -0,0 +1,245 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/emicklei/proto"
+)
+
+var protoScalarTypes map[string]string = map[string]string{
+	"double":   "float",
+	"float":    "float",
+	"int32":    "int",
+	"uint32":   "int",
+	"sint32":   "int",
+	"fixed32":  "int",
+	"sfixed32": "int",
+	"int64":    "long",
+	"uint64":   "long",
+	"sint64":   "long",
+	"fixed64":  "long",
+	"sfixed64": "long",
+	"bool":     "boolean",
+	"string":   "string",
+	"bytes":    "byte",
+}
+
+//Well known types with a levo equivalent. The wrapper types stand for
+//values that may be null, so their properties are Nullable.
+var protoWellKnownTypes map[string]string = map[string]string{
+	"google.protobuf.Timestamp":   "date",
+	"google.protobuf.DoubleValue": "float",
+	"google.protobuf.FloatValue":  "float",
+	"google.protobuf.Int32Value":  "int",
+	"google.protobuf.UInt32Value": "int",
+	"google.protobuf.Int64Value":  "long",
+	"google.protobuf.UInt64Value": "long",
+	"google.protobuf.BoolValue":   "boolean",
+	"google.protobuf.StringValue": "string",
+	"google.protobuf.BytesValue":  "byte",
+}
+
+//ProtoAdapter imports the messages of a .proto file (proto2 or proto3).
+//Each message becomes a model and each enum an Enum; nested ones are named
+//after the types containing them, e.g. Outer.Inner becomes OuterInner.
+//repeated fields set IsSetType, optional fields and the members of a oneof
+//are Optional and wrapper types are Nullable. Types from imported files
+//can't be resolved.
+type ProtoAdapter struct{}
+
+func (self *ProtoAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	protoFile, err := os.Open(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	defer protoFile.Close()
+	return self.ParseProto(protoFile)
+}
+
+func (self *ProtoAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	return self.ParseProto(strings.NewReader(schemaString))
+}
+
+//ParseProto lists constructs that have no levo equivalent, such as maps,
+//in the Unsupported field of the Schema built from the rest of the file.
+func (self *ProtoAdapter) ParseProto(reader io.Reader) (Schema, error) {
+	definition, err := proto.NewParser(reader).Parse()
+	if err != nil {
+		return Schema{}, err
+	}
+
+	importer := &protoImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, TypeNames: make(map[string]string)}
+	for _, element := range definition.Elements {
+		if protoPackage, ok := element.(*proto.Package); ok {
+			importer.Package = protoPackage.Name
+		}
+	}
+	importer.Schema.Project = importer.Package
+	importer.collectTypeNames(definition.Elements, "")
+	importer.importElements(definition.Elements, "")
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+type protoImporter struct {
+	Schema    Schema
+	Package   string
+	TypeNames map[string]string
+}
+
+//collectTypeNames maps the name of every message and enum, qualified by
+//the messages it is nested in, to the name of its model or enum.
+func (self *protoImporter) collectTypeNames(elements []proto.Visitee, scope string) {
+	for _, element := range elements {
+		switch typedElement := element.(type) {
+		case *proto.Message:
+			if typedElement.IsExtend {
+				continue
+			}
+			qualifiedName := qualifyProtoName(scope, typedElement.Name)
+			self.TypeNames[qualifiedName] = strings.Replace(qualifiedName, ".", "", -1)
+			self.collectTypeNames(typedElement.Elements, qualifiedName)
+		case *proto.Enum:
+			qualifiedName := qualifyProtoName(scope, typedElement.Name)
+			self.TypeNames[qualifiedName] = strings.Replace(qualifiedName, ".", "", -1)
+		}
+	}
+}
+
+func (self *protoImporter) importElements(elements []proto.Visitee, scope string) {
+	for _, element := range elements {
+		switch typedElement := element.(type) {
+		case *proto.Message:
+			if typedElement.IsExtend {
+				self.unsupported(qualifyProtoName(scope, typedElement.Name), "extend", "extensions can't be added to models")
+				continue
+			}
+			self.importMessage(typedElement, scope)
+		case *proto.Enum:
+			self.importEnum(typedElement, scope)
+		}
+	}
+}
+
+func (self *protoImporter) importMessage(message *proto.Message, scope string) {
+	qualifiedName := qualifyProtoName(scope, message.Name)
+	model := Model{Name: self.TypeNames[qualifiedName], Properties: make([]ModelProperty, 0)}
+	//Reserve the model's place so that it comes before the types nested in
+	//it.
+	index := len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, model)
+
+	for _, element := range message.Elements {
+		switch field := element.(type) {
+		case *proto.NormalField:
+			if property, ok := self.property(field.Field, qualifiedName); ok {
+				property.IsSetType = field.Repeated
+				property.Optional = field.Optional
+				model.Properties = append(model.Properties, property)
+			}
+		case *proto.Oneof:
+			for _, oneofElement := range field.Elements {
+				if oneofField, ok := oneofElement.(*proto.OneOfField); ok {
+					if property, ok := self.property(oneofField.Field, qualifiedName); ok {
+						property.Optional = true
+						model.Properties = append(model.Properties, property)
+					}
+				}
+			}
+		case *proto.MapField:
+			self.unsupported(qualifiedName+"."+field.Name, "map", "properties can't be maps")
+		case *proto.Group:
+			self.unsupported(qualifiedName+"."+field.Name, "group", "groups can't be imported")
+		}
+	}
+	self.Schema.Models[index] = model
+	self.importElements(message.Elements, qualifiedName)
+}
+
+func (self *protoImporter) property(field *proto.Field, scope string) (ModelProperty, bool) {
+	path := scope + "." + field.Name
+	property := ModelProperty{RemoteIdentifier: field.Name, LocalIdentifier: field.Name}
+	fieldType := strings.TrimPrefix(field.Type, ".")
+	if scalarType, ok := protoScalarTypes[field.Type]; ok {
+		property.PropertyType = scalarType
+		return property, true
+	}
+	if wellKnownType, ok := protoWellKnownTypes[fieldType]; ok {
+		property.PropertyType = wellKnownType
+		property.Nullable = wellKnownType != "date"
+		return property, true
+	}
+	if typeName, ok := self.resolveTypeName(field.Type, scope); ok {
+		property.PropertyType = typeName
+		return property, true
+	}
+	self.unsupported(path, "type", "type "+field.Type+" is not defined in this file")
+	return property, false
+}
+
+//resolveTypeName looks typeName up the way protoc does: relative to the
+//innermost enclosing message first, then to each message around it.
+func (self *protoImporter) resolveTypeName(typeName string, scope string) (string, bool) {
+	if strings.HasPrefix(typeName, ".") {
+		typeName = typeName[1:]
+		scope = ""
+	}
+	if self.Package != "" && strings.HasPrefix(typeName, self.Package+".") {
+		if name, ok := self.TypeNames[typeName[len(self.Package)+1:]]; ok {
+			return name, true
+		}
+	}
+	for {
+		if name, ok := self.TypeNames[qualifyProtoName(scope, typeName)]; ok {
+			return name, true
+		}
+		if scope == "" {
+			return "", false
+		}
+		if lastDot := strings.LastIndex(scope, "."); lastDot >= 0 {
+			scope = scope[:lastDot]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+func (self *protoImporter) importEnum(protoEnum *proto.Enum, scope string) {
+	enum := Enum{Name: self.TypeNames[qualifyProtoName(scope, protoEnum.Name)], Type: "int", Values: make([]EnumValue, 0)}
+	for _, element := range protoEnum.Elements {
+		if enumField, ok := element.(*proto.EnumField); ok {
+			enum.Values = append(enum.Values, EnumValue{Name: enumField.Name, RawValue: strconv.Itoa(enumField.Integer)})
+		}
+	}
+	self.Schema.Enums = append(self.Schema.Enums, enum)
+}
+
+func (self *protoImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+func qualifyProtoName(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
@@ -0,0 +1,74 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessProtoFile(testing *testing.T) {
+	adapter := GetProtoAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/schema.proto")
+	if err != nil || len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "Order.labels" {
+		testing.Errorf("Expecting the map field Order.labels to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if schema.Project != "example.store" {
+		testing.Errorf("Expecting project %v. Got %v", "example.store", schema.Project)
+	}
+
+	expectedModels := []Model{
+		{Name: "Order", Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long"},
+			{RemoteIdentifier: "customer", LocalIdentifier: "customer", PropertyType: "string"},
+			{RemoteIdentifier: "items", LocalIdentifier: "items", PropertyType: "OrderLineItem", IsSetType: true},
+			{RemoteIdentifier: "status", LocalIdentifier: "status", PropertyType: "OrderStatus"},
+			{RemoteIdentifier: "placed_at", LocalIdentifier: "placed_at", PropertyType: "date"},
+			{RemoteIdentifier: "note", LocalIdentifier: "note", PropertyType: "string", Nullable: true},
+			{RemoteIdentifier: "discount", LocalIdentifier: "discount", PropertyType: "float", Optional: true},
+			{RemoteIdentifier: "card_token", LocalIdentifier: "card_token", PropertyType: "string", Optional: true},
+			{RemoteIdentifier: "voucher", LocalIdentifier: "voucher", PropertyType: "string", Optional: true},
+		}},
+		{Name: "OrderLineItem", Properties: []ModelProperty{
+			{RemoteIdentifier: "sku", LocalIdentifier: "sku", PropertyType: "string"},
+			{RemoteIdentifier: "quantity", LocalIdentifier: "quantity", PropertyType: "int"},
+		}},
+		{Name: "Shipment", Properties: []ModelProperty{
+			{RemoteIdentifier: "order", LocalIdentifier: "order", PropertyType: "Order"},
+			{RemoteIdentifier: "items", LocalIdentifier: "items", PropertyType: "OrderLineItem", IsSetType: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	expectedEnums := []Enum{{Name: "OrderStatus", Type: "int", Values: []EnumValue{{Name: "STATUS_UNKNOWN", RawValue: "0"}, {Name: "STATUS_PLACED", RawValue: "1"}, {Name: "STATUS_SHIPPED", RawValue: "2"}}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting enums %v. Got %v", expectedEnums, schema.Enums)
+	}
+}
+
+func TestProcessProtoString(testing *testing.T) {
+	adapter := GetProtoAdapter()
+	_, err := adapter.ProcessSchemaString("syntax = \"proto3\";\nmessage Broken {\n  string name = \n}\n")
+	if err == nil {
+		testing.Errorf("Parsing an invalid .proto did not fail")
+	}
+
+	schema, err := adapter.ProcessSchemaString("syntax = \"proto3\";\nimport \"other.proto\";\nmessage Pet {\n  other.Owner owner = 1;\n}\n")
+	if err != nil || len(schema.Unsupported) == 0 || schema.Unsupported[0].Path != "Pet.owner" {
+		testing.Errorf("Expecting the imported type of Pet.owner to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+}
@@ -0,0 +1,237 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"gopkg.in/yaml.v2"
+)
+
+//UnsupportedConstruct is a part of an imported schema that has no levo
+//equivalent. Path locates it in the source document, e.g. as a JSON pointer.
+//The importers list them in Schema.Unsupported and still return the Schema
+//built from everything else, so callers may choose to carry on with it.
+type UnsupportedConstruct struct {
+	Path    string
+	Keyword string
+	Reason  string
+}
+
+//documentObject is a decoded JSON or YAML object that remembers the order
+//of its keys, so importers can keep models and properties in the order the
+//document declares them.
+type documentObject struct {
+	Keys   []string
+	Values map[string]interface{}
+}
+
+func newDocumentObject() *documentObject {
+	return &documentObject{Keys: make([]string, 0), Values: make(map[string]interface{})}
+}
+
+func (self *documentObject) Set(key string, value interface{}) {
+	if _, ok := self.Values[key]; !ok {
+		self.Keys = append(self.Keys, key)
+	}
+	self.Values[key] = value
+}
+
+func (self *documentObject) Has(key string) bool {
+	if self == nil {
+		return false
+	}
+	_, ok := self.Values[key]
+	return ok
+}
+
+func (self *documentObject) Get(key string) interface{} {
+	if self == nil {
+		return nil
+	}
+	return self.Values[key]
+}
+
+//Object returns the object stored under key, or nil.
+func (self *documentObject) Object(key string) *documentObject {
+	object, _ := self.Get(key).(*documentObject)
+	return object
+}
+
+//String returns the string stored under key, or "".
+func (self *documentObject) String(key string) string {
+	value, _ := self.Get(key).(string)
+	return value
+}
+
+//Strings returns the strings in the array stored under key.
+func (self *documentObject) Strings(key string) []string {
+	values, _ := self.Get(key).([]interface{})
+	strs := make([]string, 0, len(values))
+	for _, value := range values {
+		if str, ok := value.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+//MarshalJSON writes the object's keys in order.
+func (self *documentObject) MarshalJSON() ([]byte, error) {
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+	for index, key := range self.Keys {
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+		encodedKey, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		encodedValue, err := json.Marshal(self.Values[key])
+		if err != nil {
+			return nil, err
+		}
+		buffer.Write(encodedKey)
+		buffer.WriteString(":")
+		buffer.Write(encodedValue)
+	}
+	buffer.WriteString("}")
+	return buffer.Bytes(), nil
+}
+
+//decodeDocumentJSON decodes JSON like encoding/json does into an
+//interface{}, except that objects become *documentObject and numbers
+//json.Number.
+func decodeDocumentJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	value, err := decodeDocumentValue(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("Unexpected data after JSON document")
+	}
+	return value, nil
+}
+
+func decodeDocumentValue(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := newDocumentObject()
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, _ := keyToken.(string)
+			value, err := decodeDocumentValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(key, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		values := make([]interface{}, 0)
+		for decoder.More() {
+			value, err := decodeDocumentValue(decoder)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return values, nil
+	}
+	return token, nil
+}
+
+//decodeDocumentYAML decodes YAML into the same shapes as
+//decodeDocumentJSON, except that numbers are ints or float64s.
+func decodeDocumentYAML(data []byte) (interface{}, error) {
+	var document yaml.MapSlice
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	return convertYAMLValue(document), nil
+}
+
+func convertYAMLValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case yaml.MapSlice:
+		object := newDocumentObject()
+		for _, item := range typedValue {
+			object.Set(fmt.Sprint(item.Key), convertYAMLValue(item.Value))
+		}
+		return object
+	case map[interface{}]interface{}:
+		keys := make([]string, 0, len(typedValue))
+		items := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			keys = append(keys, fmt.Sprint(key))
+			items[fmt.Sprint(key)] = item
+		}
+		sort.Strings(keys)
+		object := newDocumentObject()
+		for _, key := range keys {
+			object.Set(key, convertYAMLValue(items[key]))
+		}
+		return object
+	case []interface{}:
+		values := make([]interface{}, 0, len(typedValue))
+		for _, item := range typedValue {
+			values = append(values, convertYAMLValue(item))
+		}
+		return values
+	}
+	return value
+}
+
+//documentScalarString formats a decoded scalar the way it was written in
+//the document.
+func documentScalarString(value interface{}) (string, bool) {
+	switch typedValue := value.(type) {
+	case string:
+		return typedValue, true
+	case json.Number:
+		return typedValue.String(), true
+	case int:
+		return strconv.Itoa(typedValue), true
+	case int64:
+		return strconv.FormatInt(typedValue, 10), true
+	case float64:
+		return strconv.FormatFloat(typedValue, 'g', -1, 64), true
+	case bool:
+		return strconv.FormatBool(typedValue), true
+	}
+	return "", false
+}
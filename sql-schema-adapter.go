@@ -0,0 +1,886 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"bitbucket.org/pkg/inflect"
+)
+
+//Column types by their first word, lower cased. Anything else falls back
+//to SQLite's type affinity rules.
+var sqlColumnTypes map[string]string = map[string]string{
+	"int": "int", "integer": "int", "int4": "int", "mediumint": "int", "smallint": "int", "int2": "int", "tinyint": "int", "serial": "int", "serial4": "int", "smallserial": "int", "year": "int",
+	"bigint": "long", "int8": "long", "bigserial": "long", "serial8": "long",
+	"real": "float", "float": "float", "float4": "float", "float8": "float", "double": "float", "decimal": "float", "dec": "float", "numeric": "float", "money": "float",
+	"bool": "boolean", "boolean": "boolean", "bit": "boolean",
+	"char": "string", "character": "string", "varchar": "string", "nchar": "string", "nvarchar": "string", "varchar2": "string", "nvarchar2": "string", "national": "string", "text": "string", "tinytext": "string", "mediumtext": "string", "longtext": "string", "ntext": "string", "clob": "string", "uuid": "string", "json": "string", "jsonb": "string", "xml": "string", "citext": "string",
+	"date": "date", "datetime": "date", "datetime2": "date", "smalldatetime": "date", "timestamp": "date", "timestamptz": "date", "time": "date", "timetz": "date",
+	"blob": "byte", "tinyblob": "byte", "mediumblob": "byte", "longblob": "byte", "bytea": "byte", "binary": "byte", "varbinary": "byte", "image": "byte",
+}
+
+//Words that end a column's type and start its constraints.
+var sqlColumnConstraintWords map[string]bool = map[string]bool{
+	"constraint": true, "not": true, "null": true, "primary": true, "references": true, "default": true, "unique": true, "check": true, "collate": true,
+	"auto_increment": true, "autoincrement": true, "generated": true, "comment": true, "on": true, "as": true, "identity": true,
+}
+
+//SQLSchemaAdapter imports the CREATE TABLE statements of a SQL DDL script,
+//as written for SQLite, PostgreSQL or MySQL. Each table becomes a model
+//named after the singular of the table name, e.g. order_items becomes
+//OrderItem, and each column a property. Nullable columns are Nullable and
+//NOT NULL and primary key columns NotNull, literal DEFAULTs become the
+//Default of their property, array columns set IsSetType and MySQL ENUM
+//columns get an Enum. Primary and foreign keys, unique constraints and
+//indexes, including those added by ALTER TABLE and CREATE INDEX, are
+//recorded on the models. Other statements are ignored.
+type SQLSchemaAdapter struct{}
+
+func (self *SQLSchemaAdapter) ProcessSchemaFile(schemaPath string) (Schema, error) {
+	fileContents, err := ioutil.ReadFile(schemaPath)
+	if err != nil {
+		return Schema{}, err
+	}
+	return self.ParseSQL(string(fileContents))
+}
+
+func (self *SQLSchemaAdapter) ProcessSchemaString(schemaString string) (Schema, error) {
+	return self.ParseSQL(schemaString)
+}
+
+//ParseSQL lists column types and statements that have no levo equivalent
+//in the Unsupported field of the Schema built from the rest of the script.
+func (self *SQLSchemaAdapter) ParseSQL(ddl string) (Schema, error) {
+	tokens, err := tokenizeSQL(ddl)
+	if err != nil {
+		return Schema{}, err
+	}
+	importer := &sqlImporter{Schema: Schema{Models: make([]Model, 0), Enums: make([]Enum, 0)}, ModelIndexes: make(map[string]int)}
+	for _, statement := range splitSQLStatements(tokens) {
+		if err := importer.importStatement(&sqlCursor{Tokens: statement}); err != nil {
+			return Schema{}, err
+		}
+	}
+	importer.checkPrimaryKeys()
+	importer.resolveForeignKeys()
+	if err := importer.Schema.validate(); err != nil {
+		return Schema{}, err
+	}
+	return importer.Schema, nil
+}
+
+type sqlImporter struct {
+	Schema       Schema
+	ModelIndexes map[string]int
+}
+
+func (self *sqlImporter) importStatement(cursor *sqlCursor) error {
+	if cursor.Keywords("create") {
+		cursor.Keywords("or", "replace")
+		for _, modifier := range []string{"temporary", "temp", "unlogged", "global", "local"} {
+			cursor.Keywords(modifier)
+		}
+		unique := cursor.Keywords("unique")
+		if cursor.Keywords("index") {
+			return self.importIndex(unique, cursor)
+		}
+		if !cursor.Keywords("table") {
+			//Views, triggers...
+			return nil
+		}
+		cursor.Keywords("if", "not", "exists")
+		tableName, err := cursor.QualifiedName()
+		if err != nil {
+			return err
+		}
+		if cursor.Peek().Text != "(" {
+			self.unsupported(tableName, "create table", "tables must list their columns")
+			return nil
+		}
+		return self.importTable(tableName, cursor)
+	}
+	if cursor.Keywords("alter", "table") {
+		cursor.Keywords("if", "exists")
+		cursor.Keywords("only")
+		tableName, err := cursor.QualifiedName()
+		if err != nil {
+			return err
+		}
+		index, ok := self.ModelIndexes[tableName]
+		if !ok {
+			self.unsupported(tableName, "alter table", "tables must be created earlier in the script")
+			return nil
+		}
+		if !cursor.Keywords("add") {
+			//Changes of owner, defaults... don't affect the model
+			return nil
+		}
+		model := &self.Schema.Models[index]
+		if cursor.PeekTableConstraint() {
+			return self.importTableConstraint(model, cursor)
+		}
+		cursor.Keywords("column")
+		cursor.Keywords("if", "not", "exists")
+		return self.importColumn(model, tableName, cursor)
+	}
+	return nil
+}
+
+func (self *sqlImporter) importTable(tableName string, cursor *sqlCursor) error {
+	if _, ok := self.ModelIndexes[tableName]; ok {
+		return errors.New("Table " + tableName + " is created twice")
+	}
+	model := Model{Name: sqlModelName(tableName), Properties: make([]ModelProperty, 0), PrimaryKey: make([]string, 0), ForeignKeys: make([]ForeignKey, 0), Indexes: make([]Index, 0)}
+	body, err := cursor.Group()
+	if err != nil {
+		return errors.New("Unable to read the columns of table " + tableName + ": " + err.Error())
+	}
+	for _, item := range splitSQLList(body) {
+		itemCursor := &sqlCursor{Tokens: item}
+		if len(item) == 0 {
+			continue
+		}
+		if itemCursor.PeekTableConstraint() {
+			if err := self.importTableConstraint(&model, itemCursor); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := self.importColumn(&model, tableName, itemCursor); err != nil {
+			return err
+		}
+	}
+	//Primary key columns can't be null, whether or not they say so
+	for index, property := range model.Properties {
+		if containsString(model.PrimaryKey, property.RemoteIdentifier) {
+			model.Properties[index].Nullable = false
+			model.Properties[index].NotNull = true
+		}
+	}
+	self.ModelIndexes[tableName] = len(self.Schema.Models)
+	self.Schema.Models = append(self.Schema.Models, model)
+	return nil
+}
+
+func (self *sqlImporter) importColumn(model *Model, tableName string, cursor *sqlCursor) error {
+	columnName := cursor.Next().Text
+	path := tableName + "." + columnName
+	property := ModelProperty{RemoteIdentifier: columnName, LocalIdentifier: columnName, Nullable: true}
+
+	typeWords := make([]string, 0)
+	typeArguments := make([]sqlToken, 0)
+	for !cursor.Done() {
+		token := cursor.Peek()
+		if token.Kind == sqlWord && !token.Quoted && !sqlColumnConstraintWords[strings.ToLower(token.Text)] {
+			if strings.ToLower(token.Text) == "character" && len(typeWords) > 0 {
+				//MySQL's CHARACTER SET
+				break
+			}
+			typeWords = append(typeWords, strings.ToLower(token.Text))
+			cursor.Next()
+		} else if token.Text == "(" && token.Kind == sqlPunctuation && len(typeWords) > 0 {
+			arguments, err := cursor.Group()
+			if err != nil {
+				return err
+			}
+			typeArguments = append(typeArguments, arguments...)
+		} else if token.Text == "[" && token.Kind == sqlPunctuation {
+			//PostgreSQL arrays, e.g. text[] or integer[3]
+			for !cursor.Done() && cursor.Peek().Text != "]" {
+				cursor.Next()
+			}
+			cursor.Next()
+			property.IsSetType = true
+		} else {
+			break
+		}
+	}
+
+	unique := false
+	literalDefault := true
+	for !cursor.Done() {
+		if cursor.Keywords("default") {
+			value, ok, err := cursor.DefaultValue()
+			if err != nil {
+				return err
+			}
+			property.Default, literalDefault = value, ok
+		} else if cursor.Keywords("not", "null") {
+			property.Nullable = false
+			property.NotNull = true
+		} else if cursor.Keywords("unique") {
+			unique = true
+		} else if cursor.Keywords("primary", "key") {
+			model.PrimaryKey = appendIfMissing(model.PrimaryKey, columnName)
+		} else if cursor.Keywords("references") {
+			foreignKey, err := cursor.References([]string{columnName})
+			if err != nil {
+				return err
+			}
+			model.ForeignKeys = append(model.ForeignKeys, foreignKey)
+		} else if cursor.Peek().Text == "(" && cursor.Peek().Kind == sqlPunctuation {
+			//CHECK and GENERATED expressions
+			if _, err := cursor.Group(); err != nil {
+				return err
+			}
+		} else {
+			cursor.Next()
+		}
+	}
+
+	if len(typeWords) == 0 {
+		self.unsupported(path, "type", "columns without a declared type")
+		return nil
+	}
+	if typeWords[0] == "enum" {
+		enum := Enum{Name: model.Name + Titlecase(columnName), Type: "string", Values: make([]EnumValue, 0)}
+		for _, argument := range typeArguments {
+			if argument.Kind == sqlString {
+				enum.Values = append(enum.Values, EnumValue{Name: argument.Text, RawValue: argument.Text})
+			}
+		}
+		self.Schema.Enums = append(self.Schema.Enums, enum)
+		property.PropertyType = enum.Name
+	} else if propertyType, ok := sqlPortableType(typeWords, typeArguments); ok {
+		property.PropertyType = propertyType
+	} else {
+		self.unsupported(path, "type", "column type "+strings.Join(typeWords, " "))
+		return nil
+	}
+	if !literalDefault {
+		self.unsupported(path, "default", "only literal defaults can be imported")
+	} else if property.Default != nil {
+		self.importDefault(&property, path)
+	}
+	model.Properties = append(model.Properties, property)
+	if unique {
+		model.Indexes = append(model.Indexes, Index{Properties: []string{columnName}, Unique: true})
+	}
+	return nil
+}
+
+//importIndex reads a CREATE INDEX statement. The UNIQUE keyword, if any,
+//has already been consumed.
+func (self *sqlImporter) importIndex(unique bool, cursor *sqlCursor) error {
+	cursor.Keywords("concurrently")
+	cursor.Keywords("if", "not", "exists")
+	index := Index{Unique: unique}
+	if !cursor.Keywords("on") {
+		name, err := cursor.QualifiedName()
+		if err != nil {
+			return err
+		}
+		index.Name = name
+		if !cursor.Keywords("on") {
+			return errors.New("Index " + name + " isn't created on a table")
+		}
+	}
+	cursor.Keywords("only")
+	tableName, err := cursor.QualifiedName()
+	if err != nil {
+		return err
+	}
+	modelIndex, ok := self.ModelIndexes[tableName]
+	if !ok {
+		self.unsupported(tableName, "create index", "tables must be created earlier in the script")
+		return nil
+	}
+	if cursor.Keywords("using") {
+		cursor.Next()
+	}
+	if index.Properties, err = cursor.ColumnList(); err != nil {
+		return err
+	}
+	self.addIndex(&self.Schema.Models[modelIndex], index)
+	return nil
+}
+
+//addIndex adds index to model unless it covers expressions rather than
+//columns.
+func (self *sqlImporter) addIndex(model *Model, index Index) {
+	for _, column := range index.Properties {
+		if !modelHasProperty(*model, column) {
+			self.unsupported(model.Name+"."+column, "index", "indexes must cover columns of the table")
+			return
+		}
+	}
+	model.Indexes = append(model.Indexes, index)
+}
+
+func (self *sqlImporter) importTableConstraint(model *Model, cursor *sqlCursor) error {
+	constraintName := ""
+	if cursor.Keywords("constraint") {
+		constraintName = cursor.Next().Text
+	}
+	if unique := cursor.Keywords("unique"); unique || cursor.Keywords("key") || cursor.Keywords("index") {
+		//MySQL's UNIQUE KEY name (...) and KEY name (...)
+		if unique && !cursor.Keywords("key") {
+			cursor.Keywords("index")
+		}
+		index := Index{Name: constraintName, Unique: unique}
+		if token := cursor.Peek(); token.Kind == sqlWord {
+			index.Name = cursor.Next().Text
+		}
+		columns, err := cursor.ColumnList()
+		if err != nil {
+			return err
+		}
+		index.Properties = columns
+		self.addIndex(model, index)
+	} else if cursor.Keywords("primary", "key") {
+		columns, err := cursor.ColumnList()
+		if err != nil {
+			return err
+		}
+		for _, column := range columns {
+			model.PrimaryKey = appendIfMissing(model.PrimaryKey, column)
+			for index, property := range model.Properties {
+				if property.RemoteIdentifier == column {
+					model.Properties[index].Nullable = false
+					model.Properties[index].NotNull = true
+				}
+			}
+		}
+	} else if cursor.Keywords("foreign", "key") {
+		columns, err := cursor.ColumnList()
+		if err != nil {
+			return err
+		}
+		if !cursor.Keywords("references") {
+			return errors.New("Foreign key of " + model.Name + " doesn't reference a table")
+		}
+		foreignKey, err := cursor.References(columns)
+		if err != nil {
+			return err
+		}
+		model.ForeignKeys = append(model.ForeignKeys, foreignKey)
+	}
+	//CHECK and full text index definitions don't affect the models
+	return nil
+}
+
+//importDefault converts the literal DEFAULT of a column to the Go type of
+//its property, dropping defaults that don't fit. SQLite and MySQL booleans
+//default to 0 or 1.
+func (self *sqlImporter) importDefault(property *ModelProperty, path string) {
+	if property.IsSetType {
+		self.unsupported(path, "default", "array columns can't have a default")
+		property.Default = nil
+		return
+	}
+	if number, ok := property.Default.(int64); ok && property.PropertyType == "boolean" && (number == 0 || number == 1) {
+		property.Default = number == 1
+	}
+	value, err := self.Schema.typedDefault(*property)
+	if err != nil {
+		self.unsupported(path, "default", "the default doesn't match the column type. "+err.Error())
+		property.Default = nil
+		return
+	}
+	property.Default = value
+}
+
+//checkPrimaryKeys drops primary keys covering columns that weren't
+//imported.
+func (self *sqlImporter) checkPrimaryKeys() {
+	for modelIndex := range self.Schema.Models {
+		model := &self.Schema.Models[modelIndex]
+		if !modelHasProperties(*model, model.PrimaryKey) {
+			self.unsupported(model.Name+"."+strings.Join(model.PrimaryKey, ","), "primary key", "primary keys must cover columns of the table")
+			model.PrimaryKey = make([]string, 0)
+		}
+	}
+}
+
+//resolveForeignKeys fills in the referenced columns of foreign keys that
+//rely on the referenced table's primary key. Foreign keys referencing
+//tables the script doesn't create, or missing columns, are reported and
+//dropped.
+func (self *sqlImporter) resolveForeignKeys() {
+	for modelIndex := range self.Schema.Models {
+		model := &self.Schema.Models[modelIndex]
+		foreignKeys := make([]ForeignKey, 0, len(model.ForeignKeys))
+		for _, foreignKey := range model.ForeignKeys {
+			path := model.Name + "." + strings.Join(foreignKey.Properties, ",")
+			referencedModel, ok := self.Schema.modelNamed(foreignKey.ReferencedModel)
+			if !ok {
+				self.unsupported(path, "references", "foreign keys must reference tables created in the script")
+				continue
+			}
+			if len(foreignKey.ReferencedProperties) == 0 {
+				foreignKey.ReferencedProperties = append([]string{}, referencedModel.PrimaryKey...)
+			}
+			if !modelHasProperties(*model, foreignKey.Properties) || !modelHasProperties(referencedModel, foreignKey.ReferencedProperties) || len(foreignKey.Properties) != len(foreignKey.ReferencedProperties) {
+				self.unsupported(path, "references", "foreign keys must pair columns of both tables")
+				continue
+			}
+			foreignKeys = append(foreignKeys, foreignKey)
+		}
+		model.ForeignKeys = foreignKeys
+	}
+}
+
+func modelHasProperties(model Model, remoteIdentifiers []string) bool {
+	for _, remoteIdentifier := range remoteIdentifiers {
+		if !modelHasProperty(model, remoteIdentifier) {
+			return false
+		}
+	}
+	return true
+}
+
+func (self *sqlImporter) unsupported(path string, keyword string, reason string) {
+	self.Schema.Unsupported = append(self.Schema.Unsupported, UnsupportedConstruct{Path: path, Keyword: keyword, Reason: reason})
+}
+
+func sqlModelName(tableName string) string {
+	return Titlecase(inflect.Singularize(tableName))
+}
+
+func sqlPortableType(typeWords []string, typeArguments []sqlToken) (string, bool) {
+	if typeWords[0] == "tinyint" && len(typeArguments) == 1 && typeArguments[0].Text == "1" {
+		//MySQL's BOOLEAN
+		return "boolean", true
+	}
+	if portableType, ok := sqlColumnTypes[typeWords[0]]; ok {
+		return portableType, true
+	}
+	declaredType := strings.ToUpper(strings.Join(typeWords, " "))
+	switch {
+	case strings.Contains(declaredType, "INT"):
+		return "int", true
+	case strings.Contains(declaredType, "CHAR"), strings.Contains(declaredType, "CLOB"), strings.Contains(declaredType, "TEXT"):
+		return "string", true
+	case strings.Contains(declaredType, "BLOB"):
+		return "byte", true
+	case strings.Contains(declaredType, "REAL"), strings.Contains(declaredType, "FLOA"), strings.Contains(declaredType, "DOUB"):
+		return "float", true
+	}
+	return "", false
+}
+
+//sqlLiteral returns the value of a single literal, optionally signed.
+func sqlLiteral(tokens []sqlToken) (interface{}, bool) {
+	sign := ""
+	if len(tokens) == 2 && tokens[0].Kind == sqlPunctuation && (tokens[0].Text == "-" || tokens[0].Text == "+") && tokens[1].Kind == sqlNumber {
+		sign = tokens[0].Text
+		tokens = tokens[1:]
+	}
+	if len(tokens) != 1 {
+		return nil, false
+	}
+	token := tokens[0]
+	switch token.Kind {
+	case sqlNumber:
+		if value, err := strconv.ParseInt(sign+token.Text, 10, 64); err == nil {
+			return value, true
+		}
+		if value, err := strconv.ParseFloat(sign+token.Text, 64); err == nil {
+			return value, true
+		}
+	case sqlString:
+		return token.Text, true
+	case sqlWord:
+		if token.Quoted {
+			return nil, false
+		}
+		switch strings.ToLower(token.Text) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		case "null":
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+func appendIfMissing(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	sqlWord = iota
+	sqlString
+	sqlNumber
+	sqlPunctuation
+)
+
+type sqlToken struct {
+	Kind   int
+	Text   string
+	Quoted bool
+}
+
+//tokenizeSQL splits a script into words, strings, numbers and punctuation.
+//Quoted identifiers ("name", `name` or [name]) are words with Quoted set.
+//Comments are dropped.
+func tokenizeSQL(sql string) ([]sqlToken, error) {
+	tokens := make([]sqlToken, 0)
+	runes := []rune(sql)
+	for index := 0; index < len(runes); {
+		current := runes[index]
+		switch {
+		case unicode.IsSpace(current):
+			index++
+		case current == '-' && index+1 < len(runes) && runes[index+1] == '-', current == '#':
+			for index < len(runes) && runes[index] != '\n' {
+				index++
+			}
+		case current == '/' && index+1 < len(runes) && runes[index+1] == '*':
+			index += 2
+			for index+1 < len(runes) && !(runes[index] == '*' && runes[index+1] == '/') {
+				index++
+			}
+			if index+1 >= len(runes) {
+				return tokens, errors.New("Unterminated comment in SQL")
+			}
+			index += 2
+		case current == '\'' || current == '"' || current == '`' || current == '[' && !previousTokenIsIdentifier(tokens):
+			closing := current
+			if current == '[' {
+				closing = ']'
+			}
+			text := make([]rune, 0)
+			index++
+			for {
+				if index >= len(runes) {
+					return tokens, errors.New("Unterminated quote in SQL")
+				}
+				if runes[index] == closing {
+					//Quotes are escaped by doubling them
+					if index+1 < len(runes) && runes[index+1] == closing && closing != ']' {
+						text = append(text, closing)
+						index += 2
+						continue
+					}
+					index++
+					break
+				}
+				text = append(text, runes[index])
+				index++
+			}
+			if current == '\'' {
+				tokens = append(tokens, sqlToken{Kind: sqlString, Text: string(text)})
+			} else {
+				tokens = append(tokens, sqlToken{Kind: sqlWord, Text: string(text), Quoted: true})
+			}
+		case unicode.IsDigit(current):
+			start := index
+			for index < len(runes) && (unicode.IsDigit(runes[index]) || runes[index] == '.') {
+				index++
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlNumber, Text: string(runes[start:index])})
+		case unicode.IsLetter(current) || current == '_':
+			start := index
+			for index < len(runes) && (unicode.IsLetter(runes[index]) || unicode.IsDigit(runes[index]) || runes[index] == '_' || runes[index] == '$') {
+				index++
+			}
+			tokens = append(tokens, sqlToken{Kind: sqlWord, Text: string(runes[start:index])})
+		default:
+			tokens = append(tokens, sqlToken{Kind: sqlPunctuation, Text: string(current)})
+			index++
+		}
+	}
+	return tokens, nil
+}
+
+//In PostgreSQL a [ after a type starts an array, not a quoted identifier.
+func previousTokenIsIdentifier(tokens []sqlToken) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+	previous := tokens[len(tokens)-1]
+	return previous.Kind == sqlWord || previous.Text == ")"
+}
+
+func splitSQLStatements(tokens []sqlToken) [][]sqlToken {
+	statements := make([][]sqlToken, 0)
+	statement := make([]sqlToken, 0)
+	for _, token := range tokens {
+		if token.Kind == sqlPunctuation && token.Text == ";" {
+			if len(statement) > 0 {
+				statements = append(statements, statement)
+			}
+			statement = make([]sqlToken, 0)
+			continue
+		}
+		statement = append(statement, token)
+	}
+	if len(statement) > 0 {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+//splitSQLList splits tokens at the commas that are not inside parentheses.
+func splitSQLList(tokens []sqlToken) [][]sqlToken {
+	items := make([][]sqlToken, 0)
+	item := make([]sqlToken, 0)
+	depth := 0
+	for _, token := range tokens {
+		if token.Kind == sqlPunctuation {
+			switch token.Text {
+			case "(":
+				depth++
+			case ")":
+				depth--
+			case ",":
+				if depth == 0 {
+					items = append(items, item)
+					item = make([]sqlToken, 0)
+					continue
+				}
+			}
+		}
+		item = append(item, token)
+	}
+	return append(items, item)
+}
+
+type sqlCursor struct {
+	Tokens   []sqlToken
+	Position int
+}
+
+func (self *sqlCursor) Done() bool {
+	return self.Position >= len(self.Tokens)
+}
+
+func (self *sqlCursor) Peek() sqlToken {
+	if self.Done() {
+		return sqlToken{}
+	}
+	return self.Tokens[self.Position]
+}
+
+func (self *sqlCursor) Next() sqlToken {
+	token := self.Peek()
+	self.Position++
+	return token
+}
+
+//Keywords consumes words, ignoring case, if the cursor is at all of them.
+func (self *sqlCursor) Keywords(words ...string) bool {
+	for index, word := range words {
+		position := self.Position + index
+		if position >= len(self.Tokens) {
+			return false
+		}
+		token := self.Tokens[position]
+		if token.Kind != sqlWord || token.Quoted || !strings.EqualFold(token.Text, word) {
+			return false
+		}
+	}
+	self.Position += len(words)
+	return true
+}
+
+func (self *sqlCursor) Punctuation(text string) bool {
+	if token := self.Peek(); token.Kind == sqlPunctuation && token.Text == text {
+		self.Position++
+		return true
+	}
+	return false
+}
+
+//PeekAt returns the token offset tokens after the cursor without consuming
+//anything.
+func (self *sqlCursor) PeekAt(offset int) sqlToken {
+	if self.Position+offset >= len(self.Tokens) {
+		return sqlToken{}
+	}
+	return self.Tokens[self.Position+offset]
+}
+
+//PeekTableConstraint reports whether the cursor is at a table constraint
+//rather than a column definition. Most constraint words are also valid
+//column names, e.g. key VARCHAR(20), so the tokens after the word must fit
+//the constraint's grammar too.
+func (self *sqlCursor) PeekTableConstraint() bool {
+	switch self.peekKeyword(0) {
+	case "constraint":
+		switch self.peekKeyword(2) {
+		case "primary", "foreign", "unique", "check", "exclude":
+			return self.PeekAt(1).Kind == sqlWord
+		}
+		return false
+	case "primary", "foreign":
+		return self.peekKeyword(1) == "key"
+	case "check":
+		return self.peekPunctuation(1, "(")
+	case "exclude":
+		return self.peekKeyword(1) == "using" || self.peekPunctuation(1, "(")
+	case "unique", "fulltext", "spatial":
+		if keyword := self.peekKeyword(1); keyword == "key" || keyword == "index" {
+			return true
+		}
+		return self.peekIndexColumns(1)
+	case "key", "index":
+		return self.peekIndexColumns(1)
+	}
+	return false
+}
+
+//peekIndexColumns reports whether the tokens offset tokens after the cursor
+//are an index's column list, optionally preceded by its name. A column
+//list starts with a column name, unlike the arguments of a type such as
+//VARCHAR(20).
+func (self *sqlCursor) peekIndexColumns(offset int) bool {
+	if self.PeekAt(offset).Kind == sqlWord && self.PeekAt(offset).Text != "" {
+		offset++
+	}
+	next := self.PeekAt(offset + 1)
+	return self.peekPunctuation(offset, "(") && next.Kind == sqlWord && next.Text != ""
+}
+
+//peekKeyword returns the unquoted word offset tokens after the cursor in
+//lower case, or "" if there isn't one.
+func (self *sqlCursor) peekKeyword(offset int) string {
+	token := self.PeekAt(offset)
+	if token.Kind != sqlWord || token.Quoted {
+		return ""
+	}
+	return strings.ToLower(token.Text)
+}
+
+func (self *sqlCursor) peekPunctuation(offset int, text string) bool {
+	token := self.PeekAt(offset)
+	return token.Kind == sqlPunctuation && token.Text == text
+}
+
+//QualifiedName reads a possibly schema qualified name and returns its last
+//part.
+func (self *sqlCursor) QualifiedName() (string, error) {
+	token := self.Next()
+	if token.Kind != sqlWord {
+		return "", errors.New("Expecting a table name in SQL. Got " + token.Text)
+	}
+	name := token.Text
+	for self.Punctuation(".") {
+		token = self.Next()
+		if token.Kind != sqlWord {
+			return "", errors.New("Expecting a table name in SQL. Got " + token.Text)
+		}
+		name = token.Text
+	}
+	return name, nil
+}
+
+//Group consumes a parenthesised group and returns the tokens inside it.
+func (self *sqlCursor) Group() ([]sqlToken, error) {
+	if self.Peek().Text != "(" || self.Peek().Kind != sqlPunctuation {
+		return []sqlToken{}, errors.New("Expecting ( in SQL. Got " + self.Peek().Text)
+	}
+	start := self.Position + 1
+	depth := 0
+	for !self.Done() {
+		token := self.Next()
+		if token.Kind != sqlPunctuation {
+			continue
+		}
+		if token.Text == "(" {
+			depth++
+		} else if token.Text == ")" {
+			depth--
+			if depth == 0 {
+				return self.Tokens[start : self.Position-1], nil
+			}
+		}
+	}
+	return []sqlToken{}, errors.New("Unbalanced parentheses in SQL")
+}
+
+//DefaultValue consumes the expression after DEFAULT. Literals, which may
+//be parenthesised, are returned with ok set: an int64 or float64 for
+//numbers, a string, a bool for TRUE and FALSE or nil for NULL. Anything
+//else, such as CURRENT_TIMESTAMP or nextval('ids'), is consumed and
+//returned without ok.
+func (self *sqlCursor) DefaultValue() (interface{}, bool, error) {
+	tokens := make([]sqlToken, 0)
+	if self.peekPunctuation(0, "(") {
+		group, err := self.Group()
+		if err != nil {
+			return nil, false, err
+		}
+		tokens = group
+	} else {
+		if self.peekPunctuation(0, "-") || self.peekPunctuation(0, "+") {
+			tokens = append(tokens, self.Next())
+		}
+		tokens = append(tokens, self.Next())
+		if self.peekPunctuation(0, "(") {
+			//A function call
+			_, err := self.Group()
+			return nil, false, err
+		}
+	}
+	//Operators make the literal part of a larger expression. Casts, as in
+	//'x'::text, leave it alone.
+	if next := self.Peek(); next.Kind == sqlPunctuation && strings.Contains("+-*/%|", next.Text) {
+		return nil, false, nil
+	}
+	value, ok := sqlLiteral(tokens)
+	return value, ok, nil
+}
+
+//ColumnList reads a parenthesised list of column names, ignoring the sort
+//orders and prefix lengths index definitions may add.
+func (self *sqlCursor) ColumnList() ([]string, error) {
+	group, err := self.Group()
+	if err != nil {
+		return []string{}, err
+	}
+	columns := make([]string, 0)
+	for _, item := range splitSQLList(group) {
+		if len(item) > 0 && item[0].Kind == sqlWord {
+			columns = append(columns, item[0].Text)
+		}
+	}
+	return columns, nil
+}
+
+//References reads the table and optional columns after REFERENCES.
+func (self *sqlCursor) References(columns []string) (ForeignKey, error) {
+	tableName, err := self.QualifiedName()
+	if err != nil {
+		return ForeignKey{}, err
+	}
+	foreignKey := ForeignKey{Properties: columns, ReferencedModel: sqlModelName(tableName), ReferencedProperties: make([]string, 0)}
+	if self.Peek().Text == "(" && self.Peek().Kind == sqlPunctuation {
+		if foreignKey.ReferencedProperties, err = self.ColumnList(); err != nil {
+			return ForeignKey{}, err
+		}
+	}
+	return foreignKey, nil
+}
@@ -0,0 +1,180 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessSQLFile(testing *testing.T) {
+	adapter := GetSQLSchemaAdapter()
+	schema, err := adapter.ProcessSchemaFile("test-resources/schema.sql")
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(schema.Unsupported) != 1 || schema.Unsupported[0].Path != "accounts.created_at" || schema.Unsupported[0].Keyword != "default" {
+		testing.Errorf("Expecting the CURRENT_TIMESTAMP default of accounts.created_at to be unsupported. Got %v", schema.Unsupported)
+	}
+
+	expectedModels := []Model{
+		{Name: "Account", PrimaryKey: []string{"id"}, ForeignKeys: []ForeignKey{}, Indexes: []Index{{Properties: []string{"email"}, Unique: true}}, Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "int", NotNull: true},
+			{RemoteIdentifier: "email", LocalIdentifier: "email", PropertyType: "string", NotNull: true},
+			{RemoteIdentifier: "display_name", LocalIdentifier: "display_name", PropertyType: "string", Nullable: true},
+			{RemoteIdentifier: "active", LocalIdentifier: "active", PropertyType: "boolean", NotNull: true, Default: true},
+			{RemoteIdentifier: "created_at", LocalIdentifier: "created_at", PropertyType: "date", Nullable: true},
+		}},
+		{Name: "OrderItem", PrimaryKey: []string{"order_id", "line"}, Indexes: []Index{{Name: "order_items_account", Properties: []string{"account_id"}}}, Properties: []ModelProperty{
+			{RemoteIdentifier: "order_id", LocalIdentifier: "order_id", PropertyType: "long", NotNull: true},
+			{RemoteIdentifier: "line", LocalIdentifier: "line", PropertyType: "int", NotNull: true},
+			{RemoteIdentifier: "account_id", LocalIdentifier: "account_id", PropertyType: "int", Nullable: true},
+			{RemoteIdentifier: "price", LocalIdentifier: "price", PropertyType: "float", Nullable: true},
+			{RemoteIdentifier: "tags", LocalIdentifier: "tags", PropertyType: "string", IsSetType: true, Nullable: true},
+			{RemoteIdentifier: "status", LocalIdentifier: "status", PropertyType: "OrderItemStatus", NotNull: true},
+		}, ForeignKeys: []ForeignKey{
+			{Properties: []string{"account_id"}, ReferencedModel: "Account", ReferencedProperties: []string{"id"}},
+			{Properties: []string{"order_id"}, ReferencedModel: "Order", ReferencedProperties: []string{"id"}},
+		}},
+		{Name: "Order", PrimaryKey: []string{"id"}, ForeignKeys: []ForeignKey{}, Indexes: []Index{{Name: "orders_placed", Properties: []string{"placed", "notes"}, Unique: true}}, Properties: []ModelProperty{
+			{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long", NotNull: true},
+			{RemoteIdentifier: "placed", LocalIdentifier: "placed", PropertyType: "date", Nullable: true},
+			{RemoteIdentifier: "notes", LocalIdentifier: "notes", PropertyType: "string", Nullable: true},
+		}},
+	}
+	if !reflect.DeepEqual(schema.Models, expectedModels) {
+		testing.Errorf("Expecting models %v. Got %v", expectedModels, schema.Models)
+	}
+
+	expectedEnums := []Enum{{Name: "OrderItemStatus", Type: "string", Values: []EnumValue{{Name: "open", RawValue: "open"}, {Name: "shipped", RawValue: "shipped"}}}}
+	if !reflect.DeepEqual(schema.Enums, expectedEnums) {
+		testing.Errorf("Expecting enums %v. Got %v", expectedEnums, schema.Enums)
+	}
+}
+
+func TestProcessSQLString(testing *testing.T) {
+	adapter := GetSQLSchemaAdapter()
+	_, err := adapter.ProcessSchemaString("CREATE TABLE broken (id int")
+	if err == nil {
+		testing.Errorf("Parsing unbalanced SQL did not fail")
+	}
+
+	schema, err := adapter.ProcessSchemaString("CREATE TABLE shapes (id int PRIMARY KEY, outline geometry, anything);")
+	if err != nil || len(schema.Unsupported) != 2 {
+		testing.Errorf("Expecting the geometry and untyped columns to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) != 1 || len(schema.Models[0].Properties) != 1 {
+		testing.Errorf("Expecting model Shape with only the id property. Got %v", schema.Models)
+	}
+}
+
+func TestProcessSQLDefaults(testing *testing.T) {
+	adapter := GetSQLSchemaAdapter()
+	schema, err := adapter.ProcessSchemaString(`CREATE TABLE items (
+  qty INTEGER DEFAULT 5,
+  tag TEXT NOT NULL DEFAULT 'x',
+  delta INT DEFAULT -2,
+  price REAL DEFAULT (1.5),
+  code VARCHAR(8) DEFAULT 'it''s'::character varying,
+  shipped BOOLEAN DEFAULT FALSE,
+  note TEXT DEFAULT NULL,
+  id BIGINT DEFAULT nextval('items_id_seq'),
+  total INT DEFAULT 1 + 1,
+  size INT DEFAULT 'big'
+);`)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	unsupportedPaths := make([]string, 0)
+	for _, construct := range schema.Unsupported {
+		unsupportedPaths = append(unsupportedPaths, construct.Path+" "+construct.Keyword)
+	}
+	expectedPaths := []string{"items.id default", "items.total default", "items.size default"}
+	if !reflect.DeepEqual(unsupportedPaths, expectedPaths) {
+		testing.Errorf("Expecting unsupported %v. Got %v", expectedPaths, unsupportedPaths)
+	}
+
+	expectedProperties := []ModelProperty{
+		{RemoteIdentifier: "qty", LocalIdentifier: "qty", PropertyType: "int", Nullable: true, Default: int64(5)},
+		{RemoteIdentifier: "tag", LocalIdentifier: "tag", PropertyType: "string", NotNull: true, Default: "x"},
+		{RemoteIdentifier: "delta", LocalIdentifier: "delta", PropertyType: "int", Nullable: true, Default: int64(-2)},
+		{RemoteIdentifier: "price", LocalIdentifier: "price", PropertyType: "float", Nullable: true, Default: 1.5},
+		{RemoteIdentifier: "code", LocalIdentifier: "code", PropertyType: "string", Nullable: true, Default: "it's"},
+		{RemoteIdentifier: "shipped", LocalIdentifier: "shipped", PropertyType: "boolean", Nullable: true, Default: false},
+		{RemoteIdentifier: "note", LocalIdentifier: "note", PropertyType: "string", Nullable: true},
+		{RemoteIdentifier: "id", LocalIdentifier: "id", PropertyType: "long", Nullable: true},
+		{RemoteIdentifier: "total", LocalIdentifier: "total", PropertyType: "int", Nullable: true},
+		{RemoteIdentifier: "size", LocalIdentifier: "size", PropertyType: "int", Nullable: true},
+	}
+	if len(schema.Models) != 1 || !reflect.DeepEqual(schema.Models[0].Properties, expectedProperties) {
+		testing.Errorf("Expecting properties %v. Got %v", expectedProperties, schema.Models)
+	}
+}
+
+func TestProcessSQLKeysAndIndexes(testing *testing.T) {
+	adapter := GetSQLSchemaAdapter()
+	schema, err := adapter.ProcessSchemaString(`CREATE TABLE users (
+  id INT,
+  login VARCHAR(20),
+  shape GEOMETRY,
+  team_id INT REFERENCES teams (id),
+  CONSTRAINT users_login UNIQUE (login),
+  KEY users_team (team_id),
+  PRIMARY KEY (id, shape)
+);
+CREATE INDEX users_lower_login ON users (lower(login));`)
+	if err != nil || len(schema.Unsupported) != 4 {
+		testing.Errorf("Expecting the geometry column, its key, the expression index and the unknown table to be unsupported. Got %v, %v", schema.Unsupported, err)
+	}
+	if len(schema.Models) != 1 {
+		testing.Fatalf("Expecting %v models. Got %v", 1, len(schema.Models))
+	}
+	model := schema.Models[0]
+	expectedIndexes := []Index{{Name: "users_login", Properties: []string{"login"}, Unique: true}, {Name: "users_team", Properties: []string{"team_id"}}}
+	if !reflect.DeepEqual(model.Indexes, expectedIndexes) {
+		testing.Errorf("Expecting indexes %v. Got %v", expectedIndexes, model.Indexes)
+	}
+	if len(model.PrimaryKey) != 0 || len(model.ForeignKeys) != 0 {
+		testing.Errorf("Expecting the primary and foreign keys to be dropped. Got %v and %v", model.PrimaryKey, model.ForeignKeys)
+	}
+}
+
+func TestProcessSQLColumnsNamedLikeConstraints(testing *testing.T) {
+	adapter := GetSQLSchemaAdapter()
+	schema, err := adapter.ProcessSchemaString(`CREATE TABLE settings (
+  id INT PRIMARY KEY,
+  key VARCHAR(20),
+  value TEXT,
+  index INT,
+  check BOOLEAN,
+  UNIQUE KEY settings_key (key)
+);`)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	model := schema.Models[0]
+	columns := make([]string, 0)
+	for _, property := range model.Properties {
+		columns = append(columns, property.RemoteIdentifier+" "+property.PropertyType)
+	}
+	expectedColumns := []string{"id int", "key string", "value string", "index int", "check boolean"}
+	if !reflect.DeepEqual(columns, expectedColumns) {
+		testing.Errorf("Expecting columns %v. Got %v", expectedColumns, columns)
+	}
+	expectedIndexes := []Index{{Name: "settings_key", Properties: []string{"key"}, Unique: true}}
+	if !reflect.DeepEqual(model.Indexes, expectedIndexes) {
+		testing.Errorf("Expecting indexes %v. Got %v", expectedIndexes, model.Indexes)
+	}
+}
@@ -0,0 +1,125 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+//A StaleFile was recorded in a previous manifest but is no longer
+//generated. Modified is set when the file on disk no longer matches what
+//levolib wrote, meaning someone has edited it since.
+type StaleFile struct {
+	Path     string
+	Modified bool
+}
+
+//ManifestForGeneratedFiles records the output of a plain ProcessMappings
+//run so that it can later be passed to FindStaleFiles.
+func ManifestForGeneratedFiles(files []GeneratedFile) (Manifest, error) {
+	entry := ManifestEntry{Files: make([]ManifestFile, 0, len(files))}
+	for _, file := range files {
+		relativePath, err := file.RelativePath()
+		if err != nil {
+			return Manifest{}, err
+		}
+		body, err := file.DecodedBody()
+		if err != nil {
+			return Manifest{}, err
+		}
+		entry.Files = append(entry.Files, ManifestFile{Path: filepath.ToSlash(relativePath), Hash: hashBytes(body)})
+	}
+	return Manifest{LibraryVersion: LibraryVersion, Entries: []ManifestEntry{entry}}, nil
+}
+
+//FindStaleFiles lists the files recorded in the previous manifest that the
+//current manifest no longer produces and that still exist on disk. Files
+//that levolib did not record are never returned.
+func FindStaleFiles(outputDirectory string, previous Manifest, current Manifest) ([]StaleFile, error) {
+	currentFiles := current.fileHashes()
+	staleFiles := make([]StaleFile, 0)
+	for path, hash := range previous.fileHashes() {
+		if _, ok := currentFiles[path]; ok {
+			continue
+		}
+		relativePath, err := GeneratedFile{FileName: filepath.FromSlash(path)}.RelativePath()
+		if err != nil {
+			return []StaleFile{}, err
+		}
+		fileContents, err := ioutil.ReadFile(filepath.Join(outputDirectory, relativePath))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return []StaleFile{}, err
+		}
+		staleFiles = append(staleFiles, StaleFile{Path: path, Modified: hashBytes(fileContents) != hash})
+	}
+	sort.Sort(staleFilesByPath(staleFiles))
+	return staleFiles, nil
+}
+
+//RemoveStaleFiles deletes the unmodified stale files, along with any
+//directories left empty, and returns the paths it removed. Modified files
+//are left for the user to deal with.
+func RemoveStaleFiles(outputDirectory string, staleFiles []StaleFile) ([]string, error) {
+	removed := make([]string, 0)
+	for _, staleFile := range staleFiles {
+		if staleFile.Modified {
+			continue
+		}
+		relativePath, err := GeneratedFile{FileName: filepath.FromSlash(staleFile.Path)}.RelativePath()
+		if err != nil {
+			return removed, err
+		}
+		if err := os.Remove(filepath.Join(outputDirectory, relativePath)); err != nil && !os.IsNotExist(err) {
+			return removed, err
+		}
+		removed = append(removed, staleFile.Path)
+		removeEmptyDirectories(outputDirectory, filepath.Dir(relativePath))
+	}
+	return removed, nil
+}
+
+func removeEmptyDirectories(outputDirectory string, relativeDirectory string) {
+	for relativeDirectory != "." && relativeDirectory != string(filepath.Separator) {
+		//Remove fails on directories that still have something in them
+		if err := os.Remove(filepath.Join(outputDirectory, relativeDirectory)); err != nil {
+			return
+		}
+		relativeDirectory = filepath.Dir(relativeDirectory)
+	}
+}
+
+//fileHashes maps each recorded path to the hash of its last recorded
+//contents. When several entries produce the same path the last one is
+//what ended up on disk.
+func (self *Manifest) fileHashes() map[string]string {
+	hashes := make(map[string]string)
+	for _, entry := range self.Entries {
+		for _, file := range entry.Files {
+			hashes[file.Path] = file.Hash
+		}
+	}
+	return hashes
+}
+
+type staleFilesByPath []StaleFile
+
+func (self staleFilesByPath) Len() int           { return len(self) }
+func (self staleFilesByPath) Swap(i, j int)      { self[i], self[j] = self[j], self[i] }
+func (self staleFilesByPath) Less(i, j int) bool { return self[i].Path < self[j].Path }
@@ -0,0 +1,106 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindAndRemoveStaleFiles(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+
+	previousFiles := []GeneratedFile{
+		GeneratedFile{FileName: "Kept.java", Directory: "src", Body: []byte("class Kept {}")},
+		GeneratedFile{FileName: "Removed.java", Directory: "src/removed", Body: []byte("class Removed {}")},
+		GeneratedFile{FileName: "Edited.java", Directory: "src", Body: []byte("class Edited {}")},
+	}
+	if _, err := WriteGeneratedFiles(&writer, previousFiles); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	ioutil.WriteFile(filepath.Join(outputDirectory, "src", "Edited.java"), []byte("class Edited { int mine; }"), 0644)
+	ioutil.WriteFile(filepath.Join(outputDirectory, "src", "Handwritten.java"), []byte("class Handwritten {}"), 0644)
+
+	previousManifest, err := ManifestForGeneratedFiles(previousFiles)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	currentManifest, err := ManifestForGeneratedFiles(previousFiles[:1])
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+
+	staleFiles, err := FindStaleFiles(outputDirectory, previousManifest, currentManifest)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(staleFiles) != 2 {
+		testing.Fatalf("Expecting %v stale files. Got %v", 2, staleFiles)
+	}
+	if staleFiles[0].Path != "src/Edited.java" || !staleFiles[0].Modified {
+		testing.Errorf("Expecting modified stale file src/Edited.java. Got %v", staleFiles[0])
+	}
+	if staleFiles[1].Path != "src/removed/Removed.java" || staleFiles[1].Modified {
+		testing.Errorf("Expecting unmodified stale file src/removed/Removed.java. Got %v", staleFiles[1])
+	}
+
+	removed, err := RemoveStaleFiles(outputDirectory, staleFiles)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(removed) != 1 || removed[0] != "src/removed/Removed.java" {
+		testing.Errorf("Expecting only src/removed/Removed.java to be removed. Got %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(outputDirectory, "src", "removed")); !os.IsNotExist(err) {
+		testing.Errorf("Empty directory was not removed")
+	}
+	for _, fileName := range []string{"Kept.java", "Edited.java", "Handwritten.java"} {
+		if _, err := os.Stat(filepath.Join(outputDirectory, "src", fileName)); err != nil {
+			testing.Errorf("Expecting %v to be left alone", fileName)
+		}
+	}
+}
+
+func TestRegenerateIncrementallyCleansStaleFiles(testing *testing.T) {
+	outputDirectory := SetupOutputDirectory(testing)
+	defer os.RemoveAll(outputDirectory)
+	writer := GetFileSystemWriter(outputDirectory)
+	writer.CleanStaleFiles = true
+
+	SetupContext()
+	SetupTemplate()
+	SetupModel()
+	SetupMapping()
+	if _, err := RegenerateIncrementally(context, &writer); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+
+	//Drop the static template from the mapping
+	context.Mappings[0].Templates = context.Mappings[0].Templates[:1]
+	writtenFiles, err := RegenerateIncrementally(context, &writer)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(writtenFiles) != 2 || writtenFiles[1].Status != FileRemoved {
+		testing.Fatalf("Expecting the static file to be removed. Got %v", writtenFiles)
+	}
+	if _, err := os.Stat(writtenFiles[1].Path); !os.IsNotExist(err) {
+		testing.Errorf("Stale file %v still exists", writtenFiles[1].Path)
+	}
+}
@@ -22,12 +22,11 @@ import (
 	"io"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 )
 
-var CustomType map[string]map[string]string
-
 func Lower(input string) string {
 	fmt.Print("")
 	return strings.ToLower(input)
@@ -252,12 +251,27 @@ func IsSqliteType(prop ModelProperty) bool {
 	return ok && !prop.IsSetType
 }
 
+//ToSqliteType returns the column type of a property, followed by its
+//DEFAULT clause when it has one. Columns are NOT NULL only when the schema
+//says so: the property is NotNull, or has a Default and is not nullable.
 func ToSqliteType(prop ModelProperty) string {
+	theType := ""
 	if value, ok := SqliteTypes()[strings.ToLower(prop.PropertyType)]; ok {
-		return value
+		theType = value
 	} else {
-		return prop.PropertyType
+		theType = prop.PropertyType
+	}
+
+	if prop.IsSetType {
+		return theType
+	}
+	if !IsNullable(prop) && (prop.NotNull || prop.Default != nil) {
+		theType += " NOT NULL"
+	}
+	if prop.Default != nil {
+		theType += " DEFAULT " + toSqliteDefault(typedDefaultOf(prop))
 	}
+	return theType
 }
 
 func IsJavaType(prop ModelProperty) bool {
@@ -265,6 +279,8 @@ func IsJavaType(prop ModelProperty) bool {
 	return ok
 }
 
+//ToJavaType returns the Java type of a property. Primitives are boxed when
+//the property is nullable and inside Lists.
 func ToJavaType(prop ModelProperty) string {
 	theType := ""
 	if javaType, ok := JavaTypes()[strings.ToLower(prop.PropertyType)]; ok {
@@ -273,12 +289,43 @@ func ToJavaType(prop ModelProperty) string {
 		theType = prop.PropertyType
 	}
 
+	if boxedType, ok := JavaBoxedTypes()[theType]; ok && (prop.IsSetType || IsNullable(prop)) {
+		theType = boxedType
+	}
 	if prop.IsSetType {
 		return "List<" + theType + ">"
 	}
 	return theType
 }
 
+//ToJavaDefault returns the Default of a property as a Java literal, or
+//null when it has none. Enum defaults are constants of the enum type.
+func ToJavaDefault(prop ModelProperty) string {
+	defaultValue := typedDefaultOf(prop)
+	switch value := defaultValue.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(value)
+	case string:
+		if !isPortableType(prop.PropertyType) {
+			return prop.PropertyType + "." + ToJavaEnumConstant(EnumValue{Name: value})
+		}
+		if runes := []rune(value); JavaTypes()[strings.ToLower(prop.PropertyType)] == "char" && len(runes) == 1 {
+			return strconv.QuoteRune(runes[0])
+		}
+		return strconv.Quote(value)
+	}
+	literal := defaultNumberString(defaultValue)
+	switch strings.ToLower(prop.PropertyType) {
+	case "long":
+		return literal + "L"
+	case "float":
+		return literal + "f"
+	}
+	return literal
+}
+
 func ToCoreDataType(input string) string {
 	return CoreDataTypes()[strings.ToLower(input)]
 }
@@ -287,6 +334,38 @@ func ToObjectiveCType(input string) string {
 	return ObjectiveCTypes()[strings.ToLower(input)]
 }
 
+//ToObjectiveCPropertyType returns the Objective-C type of a property.
+func ToObjectiveCPropertyType(prop ModelProperty) string {
+	return ToObjectiveCType(prop.PropertyType)
+}
+
+//ToObjectiveCNullability returns the nullability attribute of a property
+//declaration, nullable or nonnull.
+func ToObjectiveCNullability(prop ModelProperty) string {
+	if IsNullable(prop) {
+		return "nullable"
+	}
+	return "nonnull"
+}
+
+//ToObjectiveCDefault returns the Default of a property as an Objective-C
+//object literal, or nil when it has none.
+func ToObjectiveCDefault(prop ModelProperty) string {
+	defaultValue := typedDefaultOf(prop)
+	switch value := defaultValue.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		if value {
+			return "@YES"
+		}
+		return "@NO"
+	case string:
+		return "@" + strconv.Quote(value)
+	}
+	return "@" + defaultNumberString(defaultValue)
+}
+
 func ToRailsType(prop ModelProperty) string {
 	theType := ""
 	if railsType, ok := RailsTypes()[strings.ToLower(prop.PropertyType)]; ok {
@@ -301,43 +380,260 @@ func ToRailsType(prop ModelProperty) string {
 	return theType
 }
 
-func RegisterCustomType(customType string) string {
-	if CustomType == nil {
-		CustomType = make(map[string]map[string]string, 0)
+func IsEnumType(prop ModelProperty, enums []Enum) bool {
+	_, ok := enumNamed(prop.PropertyType, enums)
+	return ok
+}
+
+//EnumForType returns the enum a property holds, or an empty Enum when its
+//type is not one of enums.
+func EnumForType(prop ModelProperty, enums []Enum) Enum {
+	enum, _ := enumNamed(prop.PropertyType, enums)
+	return enum
+}
+
+//EnumRawValue returns the RawValue of value, falling back to its Name in
+//string enums and its position in int and float enums.
+func EnumRawValue(enum Enum, value EnumValue) string {
+	if value.RawValue != "" {
+		return value.RawValue
 	}
+	if enum.Type == "int" || enum.Type == "float" {
+		for index, enumValue := range enum.Values {
+			if enumValue.Name == value.Name {
+				return strconv.Itoa(index)
+			}
+		}
+	}
+	return value.Name
+}
 
-	if _, ok := CustomType[customType]; !ok {
-		CustomType[customType] = make(map[string]string, 0)
+func ToJavaEnumConstant(value EnumValue) string {
+	return Upper(Snakecase(enumIdentifier(value.Name)))
+}
+
+func ToJavaEnumRawType(enum Enum) string {
+	if javaType, ok := JavaTypes()[enum.Type]; ok {
+		return javaType
 	}
-	return ""
+	return "String"
 }
 
-func SetCustomType(customType string, key string, value string) string {
-	if CustomType[customType] == nil {
-		//throw error?
-	} else {
-		CustomType[customType][key] = value
+func ToJavaEnumRawValue(enum Enum, value EnumValue) string {
+	rawValue := EnumRawValue(enum, value)
+	switch enum.Type {
+	case "int":
+		return rawValue
+	case "float":
+		return rawValue + "f"
 	}
-	return ""
+	return strconv.Quote(rawValue)
 }
 
-func IsCustomType(customType string, prop ModelProperty) bool {
-	if CustomType[customType] == nil {
-		return false
-	} else if customMap, ok := CustomType[customType]; !ok {
-		return false
-	} else if _, ok := customMap[prop.PropertyType]; !ok {
-		return false
+func ToObjectiveCEnumConstant(enum Enum, value EnumValue) string {
+	return enum.Name + Titlecase(Snakecase(enumIdentifier(value.Name)))
+}
+
+func ToObjectiveCEnumRawType(enum Enum) string {
+	switch enum.Type {
+	case "int":
+		return "NSInteger"
+	case "float":
+		return "double"
 	}
-	return true
+	return "NSString *"
 }
 
-func ToCustomType(customType string, prop ModelProperty) string {
-	if theType, ok := CustomType[customType][prop.PropertyType]; !ok {
-		return prop.PropertyType
-	} else {
-		return theType
+func ToObjectiveCEnumRawValue(enum Enum, value EnumValue) string {
+	rawValue := EnumRawValue(enum, value)
+	if enum.Type == "int" || enum.Type == "float" {
+		return rawValue
+	}
+	return "@" + strconv.Quote(rawValue)
+}
+
+//ToRailsEnumMapping returns the hash passed to ActiveRecord's enum, e.g.
+//{ active: 0, archived: 1 }.
+func ToRailsEnumMapping(enum Enum) string {
+	entries := make([]string, 0, len(enum.Values))
+	for _, value := range enum.Values {
+		rawValue := EnumRawValue(enum, value)
+		if enum.Type != "int" && enum.Type != "float" {
+			rawValue = strconv.Quote(rawValue)
+		}
+		entries = append(entries, Snakecase(enumIdentifier(value.Name))+": "+rawValue)
+	}
+	return "{ " + strings.Join(entries, ", ") + " }"
+}
+
+//IsNullable reports whether a property may hold no value: it is Nullable,
+//or Optional without a Default to fall back on and not NotNull.
+func IsNullable(prop ModelProperty) bool {
+	return prop.Nullable || (prop.Optional && prop.Default == nil && !prop.NotNull)
+}
+
+func HasDefault(prop ModelProperty) bool {
+	return prop.Default != nil
+}
+
+//typedDefaultOf returns the Default of a property converted to the Go type
+//matching its PropertyType, so integral float64 Defaults set in Go format
+//like the int64 ones the schema readers store.
+func typedDefaultOf(prop ModelProperty) interface{} {
+	if value, err := (&Schema{}).typedDefault(prop); err == nil {
+		return value
+	}
+	return prop.Default
+}
+
+func defaultNumberString(value interface{}) string {
+	switch number := value.(type) {
+	case int:
+		return strconv.Itoa(number)
+	case int64:
+		return strconv.FormatInt(number, 10)
+	case float64:
+		return strconv.FormatFloat(number, 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func toSqliteDefault(value interface{}) string {
+	switch typedValue := value.(type) {
+	case bool:
+		if typedValue {
+			return "1"
+		}
+		return "0"
+	case string:
+		return "'" + strings.Replace(typedValue, "'", "''", -1) + "'"
 	}
+	return defaultNumberString(value)
+}
+
+func IsRelationship(prop ModelProperty) bool {
+	return prop.Relationship.Kind != ""
+}
+
+//ToForeignKey returns the column holding the key of a belongsTo
+//relationship: its ForeignKey, or the property name followed by _id.
+func ToForeignKey(prop ModelProperty) string {
+	if prop.Relationship.Kind != BelongsTo {
+		return ""
+	}
+	if prop.Relationship.ForeignKey != "" {
+		return Snakecase(prop.Relationship.ForeignKey)
+	}
+	return Snakecase(propertyName(prop)) + "_id"
+}
+
+func ToCoreDataDeleteRule(prop ModelProperty) string {
+	switch prop.Relationship.OnDelete {
+	case DeleteCascade:
+		return "Cascade"
+	case DeleteDeny:
+		return "Deny"
+	case DeleteNoAction:
+		return "No Action"
+	}
+	return "Nullify"
+}
+
+//ToCoreDataRelationship returns the relationship element of a Core Data
+//model file for a relationship property.
+func ToCoreDataRelationship(prop ModelProperty) string {
+	element := "<relationship name=\"" + propertyName(prop) + "\" optional=\"" + coreDataBool(prop.Optional) + "\" toMany=\"" + coreDataBool(prop.Relationship.Kind == HasMany) + "\" deletionRule=\"" + ToCoreDataDeleteRule(prop) + "\" destinationEntity=\"" + prop.PropertyType + "\""
+	if prop.Relationship.Inverse != "" {
+		element += " inverseName=\"" + prop.Relationship.Inverse + "\" inverseEntity=\"" + prop.PropertyType + "\""
+	}
+	return element + " syncable=\"YES\"/>"
+}
+
+//ToRailsAssociation returns an ActiveRecord association declaration, e.g.
+//has_many :cats, class_name: "Cat", inverse_of: :owner, dependent: :destroy
+func ToRailsAssociation(prop ModelProperty) string {
+	relationship := prop.Relationship
+	association := ""
+	switch relationship.Kind {
+	case HasOne:
+		association = "has_one"
+	case HasMany:
+		association = "has_many"
+	case BelongsTo:
+		association = "belongs_to"
+	default:
+		return ""
+	}
+	association += " :" + Snakecase(propertyName(prop)) + ", class_name: " + strconv.Quote(prop.PropertyType)
+	if relationship.Kind == BelongsTo {
+		association += ", foreign_key: " + strconv.Quote(ToForeignKey(prop))
+	}
+	if relationship.Inverse != "" {
+		association += ", inverse_of: :" + Snakecase(relationship.Inverse)
+	}
+	switch relationship.OnDelete {
+	case DeleteCascade:
+		association += ", dependent: :destroy"
+	case DeleteNullify:
+		if relationship.Kind != BelongsTo {
+			association += ", dependent: :nullify"
+		}
+	case DeleteDeny:
+		if relationship.Kind != BelongsTo {
+			association += ", dependent: :restrict_with_error"
+		}
+	}
+	if relationship.Kind == BelongsTo && prop.Optional {
+		association += ", optional: true"
+	}
+	return association
+}
+
+func propertyName(prop ModelProperty) string {
+	if prop.LocalIdentifier != "" {
+		return prop.LocalIdentifier
+	}
+	return prop.RemoteIdentifier
+}
+
+func coreDataBool(value bool) string {
+	if value {
+		return "YES"
+	}
+	return "NO"
+}
+
+func enumNamed(name string, enums []Enum) (Enum, bool) {
+	for _, enum := range enums {
+		if enum.Name == name {
+			return enum, true
+		}
+	}
+	return Enum{}, false
+}
+
+var enumIdentifierSeparatorRegex *regexp.Regexp = regexp.MustCompile("[^A-Za-z0-9]+")
+
+//enumIdentifier makes an enum value name usable as an identifier. Values
+//are often raw strings such as "in-progress" or "1".
+func enumIdentifier(name string) string {
+	identifier := enumIdentifierSeparatorRegex.ReplaceAllString(name, "_")
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "value_" + identifier
+	}
+	return identifier
+}
+
+//isPortableType reports whether typeName is one of the types the built in
+//type helpers convert.
+func isPortableType(typeName string) bool {
+	typeName = strings.ToLower(typeName)
+	for _, types := range []map[string]string{SqliteTypes(), JavaTypes(), CoreDataTypes(), ObjectiveCTypes(), RailsTypes()} {
+		if _, ok := types[typeName]; ok {
+			return true
+		}
+	}
+	return false
 }
 
 func SHA256(data string) string {
@@ -362,11 +658,18 @@ func Truncate(targetLength int, tooLong string) string {
 	return string([]byte(tooLong)[0:targetLength])
 }
 
+//IdProp guesses the id property from a model's Properties: one named id,
+//then one whose last word is id (e.g. movieId), then the first property.
+//Use PrimaryKeyProp for models that declare their key.
 func IdProp(properties []ModelProperty) string {
-	for i := 0; i < len(properties); i++ {
-		if strings.Contains(properties[i].RemoteIdentifier, "id") {
-			underscored := Snakecase(properties[i].RemoteIdentifier)
-			return Upper(underscored)
+	for _, property := range properties {
+		if strings.EqualFold(property.RemoteIdentifier, "id") {
+			return Upper(Snakecase(property.RemoteIdentifier))
+		}
+	}
+	for _, property := range properties {
+		if words := splitByWord(property.RemoteIdentifier); len(words) > 0 && strings.EqualFold(words[len(words)-1], "id") {
+			return Upper(Snakecase(property.RemoteIdentifier))
 		}
 	}
 	if len(properties) > 0 {
@@ -377,6 +680,110 @@ func IdProp(properties []ModelProperty) string {
 	}
 }
 
+//PrimaryKeyProp returns the first property of a model's PrimaryKey,
+//falling back to IdProp for models without one.
+func PrimaryKeyProp(model Model) string {
+	if keyProperties := PrimaryKeyProps(model); len(keyProperties) > 0 {
+		return Upper(Snakecase(keyProperties[0].RemoteIdentifier))
+	}
+	return IdProp(model.Properties)
+}
+
+//PrimaryKeyProps returns the properties of a model's PrimaryKey, or its
+//property named id when it doesn't declare one. Keys and key properties
+//are inherited through ParentRef.
+func PrimaryKeyProps(model Model) []ModelProperty {
+	return primaryKeyProperties(model, parentRefOf)
+}
+
+func IsPrimaryKey(model Model, prop ModelProperty) bool {
+	for _, property := range PrimaryKeyProps(model) {
+		if property.RemoteIdentifier == prop.RemoteIdentifier {
+			return true
+		}
+	}
+	return false
+}
+
+func HasCompositeKey(model Model) bool {
+	return len(PrimaryKeyProps(model)) > 1
+}
+
+//ToSqliteTableConstraints returns the PRIMARY KEY and UNIQUE table
+//constraints of a model, e.g. PRIMARY KEY (order_id, line), UNIQUE (email)
+func ToSqliteTableConstraints(model Model) string {
+	constraints := make([]string, 0)
+	if keyProperties := PrimaryKeyProps(model); len(keyProperties) > 0 {
+		columns := make([]string, 0, len(keyProperties))
+		for _, property := range keyProperties {
+			columns = append(columns, Snakecase(property.RemoteIdentifier))
+		}
+		constraints = append(constraints, "PRIMARY KEY ("+strings.Join(columns, ", ")+")")
+	}
+	for _, index := range model.Indexes {
+		if index.Unique {
+			constraints = append(constraints, "UNIQUE ("+strings.Join(indexColumns(index), ", ")+")")
+		}
+	}
+	return strings.Join(constraints, ", ")
+}
+
+//ToSqliteCreateIndexes returns a CREATE INDEX statement for each index of
+//a model that is not a unique constraint, one per line.
+func ToSqliteCreateIndexes(tableName string, model Model) string {
+	statements := make([]string, 0)
+	for _, index := range model.Indexes {
+		if !index.Unique {
+			statements = append(statements, "CREATE INDEX IF NOT EXISTS "+indexName(tableName, index)+" ON "+tableName+" ("+strings.Join(indexColumns(index), ", ")+");")
+		}
+	}
+	return strings.Join(statements, "\n")
+}
+
+//ToRailsIndex returns the migration statement creating an index, e.g.
+//add_index :order_items, [:order_id, :line], unique: true
+func ToRailsIndex(tableName string, index Index) string {
+	columns := make([]string, 0, len(index.Properties))
+	for _, column := range indexColumns(index) {
+		columns = append(columns, ":"+column)
+	}
+	statement := "add_index :" + tableName + ", "
+	if len(columns) == 1 {
+		statement += columns[0]
+	} else {
+		statement += "[" + strings.Join(columns, ", ") + "]"
+	}
+	if index.Unique {
+		statement += ", unique: true"
+	}
+	if index.Name != "" {
+		statement += ", name: " + strconv.Quote(index.Name)
+	}
+	return statement
+}
+
+func parentRefOf(model Model) (Model, bool) {
+	if model.ParentRef == nil {
+		return Model{}, false
+	}
+	return *model.ParentRef, true
+}
+
+func indexColumns(index Index) []string {
+	columns := make([]string, 0, len(index.Properties))
+	for _, property := range index.Properties {
+		columns = append(columns, Snakecase(property))
+	}
+	return columns
+}
+
+func indexName(tableName string, index Index) string {
+	if index.Name != "" {
+		return index.Name
+	}
+	return tableName + "_" + strings.Join(indexColumns(index), "_")
+}
+
 func SqliteTypes() map[string]string {
 	var dict = make(map[string]string)
 	dict["int"] = "INTEGER"
@@ -409,6 +816,18 @@ func JavaTypes() map[string]string {
 	return dict
 }
 
+func JavaBoxedTypes() map[string]string {
+	var dict = make(map[string]string)
+	dict["int"] = "Integer"
+	dict["short"] = "Short"
+	dict["long"] = "Long"
+	dict["float"] = "Float"
+	dict["boolean"] = "Boolean"
+	dict["char"] = "Character"
+	dict["byte"] = "Byte"
+	return dict
+}
+
 func CoreDataTypes() map[string]string {
 	var dict = make(map[string]string)
 	dict["int"] = "Integer 32"
@@ -468,51 +887,74 @@ func RailsTypes() map[string]string {
 
 func addCommonUtilitiesToTemplate(templateObject *template.Template) *template.Template {
 	templateObject = templateObject.Funcs(template.FuncMap{
-		"eq":                 TestEquality,
-		"neq":                TestInequality,
-		"lower":              Lower,
-		"upper":              Upper,
-		"pluralize":          Pluralize,
-		"camelcase":          Camelcase,
-		"titlecase":          Titlecase,
-		"snakecase":          Snakecase,
-		"SHA256":             SHA256,
-		"concat":             Concat,
-		"truncate":           Truncate,
-		"registerCustomType": RegisterCustomType,
-		"setCustomType":      SetCustomType,
-		"isCustomType":       IsCustomType,
-		"toCustomType":       ToCustomType,
+		"eq":              TestEquality,
+		"neq":             TestInequality,
+		"lower":           Lower,
+		"upper":           Upper,
+		"pluralize":       Pluralize,
+		"camelcase":       Camelcase,
+		"titlecase":       Titlecase,
+		"snakecase":       Snakecase,
+		"SHA256":          SHA256,
+		"concat":          Concat,
+		"truncate":        Truncate,
+		"isEnumType":      IsEnumType,
+		"enumForType":     EnumForType,
+		"enumRawValue":    EnumRawValue,
+		"isRelationship":  IsRelationship,
+		"isNullable":      IsNullable,
+		"hasDefault":      HasDefault,
+		"primaryKeyProp":  PrimaryKeyProp,
+		"primaryKeyProps": PrimaryKeyProps,
+		"isPrimaryKey":    IsPrimaryKey,
+		"hasCompositeKey": HasCompositeKey,
+		"toForeignKey":    ToForeignKey,
 	})
 	return templateObject
 }
 
 func addJavaUtilitiesToTemplate(templateObject *template.Template) *template.Template {
 	templateObject = templateObject.Funcs(template.FuncMap{
-		"hasListType":   HasListType,
-		"isSqliteType":  IsSqliteType,
-		"toSqliteType":  ToSqliteType,
-		"sqliteType":    SqliteTypes,
-		"isJavaType":    IsJavaType,
-		"toJavaType":    ToJavaType,
-		"javaType":      JavaTypes,
-		"idProp":        IdProp,
-		"packageToPath": PackageToPath,
+		"hasListType":              HasListType,
+		"isSqliteType":             IsSqliteType,
+		"toSqliteType":             ToSqliteType,
+		"sqliteType":               SqliteTypes,
+		"isJavaType":               IsJavaType,
+		"toJavaType":               ToJavaType,
+		"javaType":                 JavaTypes,
+		"idProp":                   IdProp,
+		"packageToPath":            PackageToPath,
+		"toJavaEnumConstant":       ToJavaEnumConstant,
+		"toJavaEnumRawType":        ToJavaEnumRawType,
+		"toJavaEnumRawValue":       ToJavaEnumRawValue,
+		"toJavaDefault":            ToJavaDefault,
+		"toSqliteTableConstraints": ToSqliteTableConstraints,
+		"toSqliteCreateIndexes":    ToSqliteCreateIndexes,
 	})
 	return templateObject
 }
 
 func addObjectiveCUtilitiesToTempalte(templateObject *template.Template) *template.Template {
 	templateObject = templateObject.Funcs(template.FuncMap{
-		"toCoreDataType": ToCoreDataType,
-		"toObjectiveCType" : ToObjectiveCType,
+		"toCoreDataType":           ToCoreDataType,
+		"toObjectiveCType":         ToObjectiveCType,
+		"toObjectiveCNullability":  ToObjectiveCNullability,
+		"toObjectiveCDefault":      ToObjectiveCDefault,
+		"toObjectiveCEnumConstant": ToObjectiveCEnumConstant,
+		"toObjectiveCEnumRawType":  ToObjectiveCEnumRawType,
+		"toObjectiveCEnumRawValue": ToObjectiveCEnumRawValue,
+		"toCoreDataDeleteRule":     ToCoreDataDeleteRule,
+		"toCoreDataRelationship":   ToCoreDataRelationship,
 	})
 	return templateObject
 }
 
 func addRailsUitilitiesToTemplate(templateObject *template.Template) *template.Template {
 	templateObject = templateObject.Funcs(template.FuncMap{
-		"toRailsType": ToRailsType,
+		"toRailsType":        ToRailsType,
+		"toRailsEnumMapping": ToRailsEnumMapping,
+		"toRailsAssociation": ToRailsAssociation,
+		"toRailsIndex":       ToRailsIndex,
 	})
 	return templateObject
 }
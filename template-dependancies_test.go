@@ -15,6 +15,7 @@ limitations under the License.*/
 package levo
 
 import (
+	"strings"
 	"testing"
 	"text/template"
 )
@@ -297,3 +298,221 @@ func TestXcodeHash(testing *testing.T) {
 		testing.Errorf("Expecting %v. Got %v", 40, len(output))
 	}
 }
+
+func TestEnumHelpers(testing *testing.T) {
+	colorEnum := Enum{Name: "Color", Type: "string", Values: []EnumValue{{Name: "dark-red"}, {Name: "Green", RawValue: "green"}}}
+	gearEnum := Enum{Name: "Gear", Type: "int", Values: []EnumValue{{Name: "PARK"}, {Name: "DRIVE", RawValue: "5"}}}
+	enums := []Enum{colorEnum, gearEnum}
+	enumProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "Gear"}
+	stringProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "string"}
+
+	if isEnum := IsEnumType(enumProp, enums); isEnum != true {
+		testing.Errorf("Expecting %v. Got %v", true, isEnum)
+	}
+	if isEnum := IsEnumType(stringProp, enums); isEnum != false {
+		testing.Errorf("Expecting %v. Got %v", false, isEnum)
+	}
+	if enum := EnumForType(enumProp, enums); enum.Name != "Gear" {
+		testing.Errorf("Expecting %v. Got %v", "Gear", enum.Name)
+	}
+
+	expectedValues := [][2]string{
+		{EnumRawValue(colorEnum, colorEnum.Values[0]), "dark-red"},
+		{EnumRawValue(gearEnum, gearEnum.Values[0]), "0"},
+		{EnumRawValue(gearEnum, gearEnum.Values[1]), "5"},
+		{ToJavaEnumConstant(colorEnum.Values[0]), "DARK_RED"},
+		{ToJavaEnumConstant(EnumValue{Name: "1"}), "VALUE_1"},
+		{ToJavaEnumRawType(gearEnum), "int"},
+		{ToJavaEnumRawValue(colorEnum, colorEnum.Values[1]), "\"green\""},
+		{ToJavaEnumRawValue(gearEnum, gearEnum.Values[1]), "5"},
+		{ToObjectiveCEnumConstant(gearEnum, gearEnum.Values[0]), "GearPark"},
+		{ToObjectiveCEnumConstant(colorEnum, colorEnum.Values[0]), "ColorDarkRed"},
+		{ToObjectiveCEnumRawType(colorEnum), "NSString *"},
+		{ToObjectiveCEnumRawValue(colorEnum, colorEnum.Values[1]), "@\"green\""},
+		{ToRailsEnumMapping(gearEnum), "{ park: 0, drive: 5 }"},
+		{ToRailsEnumMapping(colorEnum), "{ dark_red: \"dark-red\", green: \"green\" }"},
+	}
+	for _, values := range expectedValues {
+		if values[0] != values[1] {
+			testing.Errorf("Expecting %v. Got %v", values[1], values[0])
+		}
+	}
+}
+
+func TestRelationshipHelpers(testing *testing.T) {
+	petsProp := ModelProperty{RemoteIdentifier: "pets", LocalIdentifier: "pets", PropertyType: "Pet", IsSetType: true, Relationship: Relationship{Kind: HasMany, Inverse: "owner", OnDelete: DeleteCascade}}
+	ownerProp := ModelProperty{RemoteIdentifier: "owner", LocalIdentifier: "owner", PropertyType: "Person", Optional: true, Relationship: Relationship{Kind: BelongsTo, Inverse: "pets"}}
+	keeperProp := ModelProperty{RemoteIdentifier: "keeper", LocalIdentifier: "keeper", PropertyType: "Person", Relationship: Relationship{Kind: BelongsTo, ForeignKey: "keeperRef"}}
+	plainProp := ModelProperty{RemoteIdentifier: "name", LocalIdentifier: "name", PropertyType: "string"}
+
+	if isRelationship := IsRelationship(petsProp); isRelationship != true {
+		testing.Errorf("Expecting %v. Got %v", true, isRelationship)
+	}
+	if isRelationship := IsRelationship(plainProp); isRelationship != false {
+		testing.Errorf("Expecting %v. Got %v", false, isRelationship)
+	}
+
+	expectedValues := [][2]string{
+		{ToForeignKey(ownerProp), "owner_id"},
+		{ToForeignKey(keeperProp), "keeper_ref"},
+		{ToForeignKey(petsProp), ""},
+		{ToCoreDataDeleteRule(petsProp), "Cascade"},
+		{ToCoreDataDeleteRule(ownerProp), "Nullify"},
+		{ToCoreDataRelationship(petsProp), "<relationship name=\"pets\" optional=\"NO\" toMany=\"YES\" deletionRule=\"Cascade\" destinationEntity=\"Pet\" inverseName=\"owner\" inverseEntity=\"Pet\" syncable=\"YES\"/>"},
+		{ToCoreDataRelationship(keeperProp), "<relationship name=\"keeper\" optional=\"NO\" toMany=\"NO\" deletionRule=\"Nullify\" destinationEntity=\"Person\" syncable=\"YES\"/>"},
+		{ToRailsAssociation(petsProp), "has_many :pets, class_name: \"Pet\", inverse_of: :owner, dependent: :destroy"},
+		{ToRailsAssociation(ownerProp), "belongs_to :owner, class_name: \"Person\", foreign_key: \"owner_id\", inverse_of: :pets, optional: true"},
+		{ToRailsAssociation(plainProp), ""},
+	}
+	for _, values := range expectedValues {
+		if values[0] != values[1] {
+			testing.Errorf("Expecting %v. Got %v", values[1], values[0])
+		}
+	}
+}
+
+func TestNullabilityAndDefaultHelpers(testing *testing.T) {
+	intProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int"}
+	nullableIntProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", Nullable: true}
+	optionalIntProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", Optional: true}
+	notNullIntProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", Optional: true, NotNull: true}
+	defaultIntProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", Optional: true, Default: int64(7)}
+	intListProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", IsSetType: true}
+	defaultStringProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "string", Nullable: true, Default: "it's"}
+	defaultBoolProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "boolean", Default: true}
+	defaultLongProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "long", Default: int64(9)}
+	defaultFloatProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "float", Default: 0.5}
+	largeIntProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int", Default: float64(10000000)}
+	defaultCharProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "char", Default: "y"}
+	defaultEnumProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "Color", Default: "dark-red"}
+
+	expectedNullability := map[*ModelProperty]bool{&intProp: false, &nullableIntProp: true, &optionalIntProp: true, &notNullIntProp: false, &defaultIntProp: false}
+	for prop, expected := range expectedNullability {
+		if nullable := IsNullable(*prop); nullable != expected {
+			testing.Errorf("Expecting %v. Got %v", expected, nullable)
+		}
+	}
+	if hasDefault := HasDefault(defaultIntProp); hasDefault != true {
+		testing.Errorf("Expecting %v. Got %v", true, hasDefault)
+	}
+
+	expectedValues := [][2]string{
+		{ToJavaType(intProp), "int"},
+		{ToJavaType(nullableIntProp), "Integer"},
+		{ToJavaType(optionalIntProp), "Integer"},
+		{ToJavaType(defaultIntProp), "int"},
+		{ToJavaType(intListProp), "List<Integer>"},
+		{ToJavaDefault(intProp), "null"},
+		{ToJavaDefault(defaultIntProp), "7"},
+		{ToJavaDefault(defaultLongProp), "9L"},
+		{ToJavaDefault(defaultFloatProp), "0.5f"},
+		{ToJavaDefault(largeIntProp), "10000000"},
+		{ToJavaDefault(defaultBoolProp), "true"},
+		{ToJavaDefault(defaultCharProp), "'y'"},
+		{ToJavaDefault(defaultStringProp), "\"it's\""},
+		{ToJavaDefault(defaultEnumProp), "Color.DARK_RED"},
+		{ToObjectiveCPropertyType(nullableIntProp), "NSNumber"},
+		{ToObjectiveCType("string"), "NSString"},
+		{ToObjectiveCNullability(intProp), "nonnull"},
+		{ToObjectiveCNullability(optionalIntProp), "nullable"},
+		{ToObjectiveCDefault(intProp), "nil"},
+		{ToObjectiveCDefault(defaultIntProp), "@7"},
+		{ToObjectiveCDefault(largeIntProp), "@10000000"},
+		{ToObjectiveCDefault(defaultBoolProp), "@YES"},
+		{ToObjectiveCDefault(defaultStringProp), "@\"it's\""},
+		{ToSqliteType(intProp), "INTEGER"},
+		{ToSqliteType(nullableIntProp), "INTEGER"},
+		{ToSqliteType(notNullIntProp), "INTEGER NOT NULL"},
+		{ToSqliteType(defaultIntProp), "INTEGER NOT NULL DEFAULT 7"},
+		{ToSqliteType(largeIntProp), "INTEGER NOT NULL DEFAULT 10000000"},
+		{ToSqliteType(defaultBoolProp), "INTEGER NOT NULL DEFAULT 1"},
+		{ToSqliteType(defaultFloatProp), "REAL NOT NULL DEFAULT 0.5"},
+		{ToSqliteType(defaultStringProp), "TEXT DEFAULT 'it''s'"},
+	}
+	for _, values := range expectedValues {
+		if values[0] != values[1] {
+			testing.Errorf("Expecting %v. Got %v", values[1], values[0])
+		}
+	}
+}
+
+func TestKeyHelpers(testing *testing.T) {
+	videoProps := []ModelProperty{{RemoteIdentifier: "video", PropertyType: "string"}, {RemoteIdentifier: "width", PropertyType: "int"}, {RemoteIdentifier: "movieId", PropertyType: "int"}}
+	idProps := append([]ModelProperty{}, videoProps...)
+	idProps = append(idProps, ModelProperty{RemoteIdentifier: "id", PropertyType: "int"})
+	orderItem := Model{Name: "OrderItem", PrimaryKey: []string{"orderId", "line"}, Properties: []ModelProperty{{RemoteIdentifier: "line", PropertyType: "int"}, {RemoteIdentifier: "orderId", PropertyType: "long"}, {RemoteIdentifier: "sku", PropertyType: "string"}}, Indexes: []Index{{Properties: []string{"sku"}, Unique: true}, {Properties: []string{"sku", "line"}}, {Name: "by_line", Properties: []string{"line"}}}}
+	video := Model{Name: "Video", Properties: idProps}
+
+	expectedValues := [][2]string{
+		{IdProp(videoProps), "MOVIE_ID"},
+		{IdProp(idProps), "ID"},
+		{IdProp(videoProps[:2]), "VIDEO"},
+		{IdProp([]ModelProperty{}), "UNKNOWN_PROPERTY"},
+		{PrimaryKeyProp(orderItem), "ORDER_ID"},
+		{PrimaryKeyProp(video), "ID"},
+		{ToSqliteTableConstraints(orderItem), "PRIMARY KEY (order_id, line), UNIQUE (sku)"},
+		{ToSqliteTableConstraints(video), "PRIMARY KEY (id)"},
+		{ToSqliteCreateIndexes("order_items", orderItem), "CREATE INDEX IF NOT EXISTS order_items_sku_line ON order_items (sku, line);\nCREATE INDEX IF NOT EXISTS by_line ON order_items (line);"},
+		{ToRailsIndex("order_items", orderItem.Indexes[0]), "add_index :order_items, :sku, unique: true"},
+		{ToRailsIndex("order_items", orderItem.Indexes[1]), "add_index :order_items, [:sku, :line]"},
+		{ToRailsIndex("order_items", orderItem.Indexes[2]), "add_index :order_items, :line, name: \"by_line\""},
+	}
+	for _, values := range expectedValues {
+		if values[0] != values[1] {
+			testing.Errorf("Expecting %v. Got %v", values[1], values[0])
+		}
+	}
+
+	if keyProps := PrimaryKeyProps(orderItem); len(keyProps) != 2 || keyProps[0].RemoteIdentifier != "orderId" {
+		testing.Errorf("Expecting %v. Got %v", orderItem.PrimaryKey, keyProps)
+	}
+	if keyProps := PrimaryKeyProps(Model{Properties: videoProps}); len(keyProps) != 0 {
+		testing.Errorf("Expecting %v key properties. Got %v", 0, len(keyProps))
+	}
+	if isKey := IsPrimaryKey(orderItem, orderItem.Properties[0]); isKey != true {
+		testing.Errorf("Expecting %v. Got %v", true, isKey)
+	}
+	if isKey := IsPrimaryKey(orderItem, orderItem.Properties[2]); isKey != false {
+		testing.Errorf("Expecting %v. Got %v", false, isKey)
+	}
+	if composite := HasCompositeKey(orderItem); composite != true {
+		testing.Errorf("Expecting %v. Got %v", true, composite)
+	}
+	if composite := HasCompositeKey(video); composite != false {
+		testing.Errorf("Expecting %v. Got %v", false, composite)
+	}
+}
+
+func TestInheritedKeyHelpers(testing *testing.T) {
+	animal := Model{Name: "Animal", PrimaryKey: []string{"uuid"}, Properties: []ModelProperty{{RemoteIdentifier: "uuid", PropertyType: "string"}, {RemoteIdentifier: "name", PropertyType: "string"}}}
+	dog := Model{Name: "Dog", Parent: "Animal", Properties: []ModelProperty{{RemoteIdentifier: "breed", PropertyType: "string"}}}
+	tag := Model{Name: "Tag", Parent: "Animal", PrimaryKey: []string{"uuid", "label"}, Properties: []ModelProperty{{RemoteIdentifier: "label", PropertyType: "string"}}}
+	schema := Schema{Models: []Model{animal, dog, tag}}
+	linkedModels := schema.withParentRefs([]Model{dog, tag})
+	dog, tag = linkedModels[0], linkedModels[1]
+
+	expectedValues := [][2]string{
+		{PrimaryKeyProp(dog), "UUID"},
+		{ToSqliteTableConstraints(dog), "PRIMARY KEY (uuid)"},
+		{PrimaryKeyProp(tag), "UUID"},
+		{ToSqliteTableConstraints(tag), "PRIMARY KEY (uuid, label)"},
+	}
+	for _, values := range expectedValues {
+		if values[0] != values[1] {
+			testing.Errorf("Expecting %v. Got %v", values[1], values[0])
+		}
+	}
+
+	if composite := HasCompositeKey(dog); composite != false {
+		testing.Errorf("Expecting %v. Got %v", false, composite)
+	}
+	if composite := HasCompositeKey(tag); composite != true {
+		testing.Errorf("Expecting %v. Got %v", true, composite)
+	}
+	if isKey := IsPrimaryKey(tag, animal.Properties[0]); isKey != true {
+		testing.Errorf("Expecting %v. Got %v", true, isKey)
+	}
+	if keyProps := schema.primaryKey(tag); strings.Join(keyProps, ",") != "uuid,label" {
+		testing.Errorf("Expecting %v. Got %v", "[uuid label]", keyProps)
+	}
+}
@@ -0,0 +1,29 @@
+-- Accounts and their orders
+CREATE TABLE IF NOT EXISTS accounts (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  email VARCHAR(255) NOT NULL UNIQUE,
+  display_name TEXT,
+  active TINYINT(1) NOT NULL DEFAULT 1,
+  created_at TIMESTAMP WITH TIME ZONE DEFAULT (CURRENT_TIMESTAMP)
+);
+
+/* Orders belong to an account */
+CREATE TABLE public.order_items (
+  "order_id" BIGINT NOT NULL,
+  `line` SMALLINT NOT NULL,
+  account_id INT REFERENCES accounts ON DELETE CASCADE,
+  price DOUBLE PRECISION,
+  tags TEXT[],
+  status ENUM('open', 'shipped') NOT NULL,
+  PRIMARY KEY (order_id, line),
+  CONSTRAINT fk_orders FOREIGN KEY (order_id) REFERENCES orders (id)
+) ENGINE=InnoDB;
+
+CREATE INDEX order_items_account ON order_items (account_id);
+
+CREATE TABLE orders (id bigserial, placed date);
+ALTER TABLE ONLY orders ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+ALTER TABLE orders ADD COLUMN notes json;
+ALTER TABLE orders OWNER TO app;
+
+CREATE UNIQUE INDEX IF NOT EXISTS orders_placed ON orders USING btree (placed, notes DESC);
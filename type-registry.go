@@ -0,0 +1,171 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"errors"
+	"sync"
+	"text/template"
+)
+
+var errFrozenTypeRegistry = errors.New("Custom types can't be changed by templates running concurrently. Add them with Context.AddCustomType")
+
+//A TypeRegistry holds custom type mappings, e.g. from levo's portable
+//types to the types of a particular language or framework. Each Context
+//owns one, so separate generations never see each other's mappings.
+//Templates use it through the registerCustomType, setCustomType,
+//isCustomType and toCustomType functions. registerCustomType and
+//setCustomType fail while templates run concurrently, since the order they
+//would apply in isn't defined; set the mappings with Context.AddCustomType
+//instead.
+type TypeRegistry struct {
+	lock   sync.RWMutex
+	types  map[string]map[string]string
+	frozen int
+}
+
+func GetTypeRegistry() *TypeRegistry {
+	return &TypeRegistry{types: make(map[string]map[string]string, 0)}
+}
+
+//defaultTypeRegistry backs the package level RegisterCustomType,
+//SetCustomType, IsCustomType and ToCustomType.
+var defaultTypeRegistry *TypeRegistry = GetTypeRegistry()
+
+func RegisterCustomType(customType string) string {
+	return defaultTypeRegistry.RegisterCustomType(customType)
+}
+
+func SetCustomType(customType string, key string, value string) string {
+	return defaultTypeRegistry.SetCustomType(customType, key, value)
+}
+
+func IsCustomType(customType string, prop ModelProperty) bool {
+	return defaultTypeRegistry.IsCustomType(customType, prop)
+}
+
+func ToCustomType(customType string, prop ModelProperty) string {
+	return defaultTypeRegistry.ToCustomType(customType, prop)
+}
+
+func (self *TypeRegistry) RegisterCustomType(customType string) string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if self.types == nil {
+		self.types = make(map[string]map[string]string, 0)
+	}
+	if _, ok := self.types[customType]; !ok {
+		self.types[customType] = make(map[string]string, 0)
+	}
+	return ""
+}
+
+//SetCustomType maps key to value for a registered custom type. Mappings
+//for types that were never registered are ignored.
+func (self *TypeRegistry) SetCustomType(customType string, key string, value string) string {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	if customMap, ok := self.types[customType]; ok {
+		customMap[key] = value
+	}
+	return ""
+}
+
+//SetCustomTypes registers customType if needed and adds all the mappings.
+func (self *TypeRegistry) SetCustomTypes(customType string, mappings map[string]string) {
+	self.RegisterCustomType(customType)
+	for key, value := range mappings {
+		self.SetCustomType(customType, key, value)
+	}
+}
+
+func (self *TypeRegistry) IsCustomType(customType string, prop ModelProperty) bool {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	if customMap, ok := self.types[customType]; !ok {
+		return false
+	} else if _, ok := customMap[prop.PropertyType]; !ok {
+		return false
+	}
+	return true
+}
+
+func (self *TypeRegistry) ToCustomType(customType string, prop ModelProperty) string {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	if theType, ok := self.types[customType][prop.PropertyType]; !ok {
+		return prop.PropertyType
+	} else {
+		return theType
+	}
+}
+
+//freeze stops templates from changing the registry until thaw is called as
+//many times.
+func (self *TypeRegistry) freeze() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.frozen++
+}
+
+func (self *TypeRegistry) thaw() {
+	self.lock.Lock()
+	defer self.lock.Unlock()
+	self.frozen--
+}
+
+func (self *TypeRegistry) isFrozen() bool {
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	return self.frozen > 0
+}
+
+//mappings returns a copy of every custom type mapping. A nil registry has
+//none.
+func (self *TypeRegistry) mappings() map[string]map[string]string {
+	mappings := make(map[string]map[string]string, 0)
+	if self == nil {
+		return mappings
+	}
+	self.lock.RLock()
+	defer self.lock.RUnlock()
+	for customType, customMap := range self.types {
+		mappings[customType] = make(map[string]string, len(customMap))
+		for key, value := range customMap {
+			mappings[customType][key] = value
+		}
+	}
+	return mappings
+}
+
+func addCustomTypeUtilitiesToTemplate(templateObject *template.Template, registry *TypeRegistry) *template.Template {
+	templateObject = templateObject.Funcs(template.FuncMap{
+		"registerCustomType": func(customType string) (string, error) {
+			if registry.isFrozen() {
+				return "", errFrozenTypeRegistry
+			}
+			return registry.RegisterCustomType(customType), nil
+		},
+		"setCustomType": func(customType string, key string, value string) (string, error) {
+			if registry.isFrozen() {
+				return "", errFrozenTypeRegistry
+			}
+			return registry.SetCustomType(customType, key, value), nil
+		},
+		"isCustomType": registry.IsCustomType,
+		"toCustomType": registry.ToCustomType,
+	})
+	return templateObject
+}
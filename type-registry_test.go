@@ -0,0 +1,126 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"strings"
+	"testing"
+)
+
+const customTypeTemplateBody string = "<<levo filename:types.txt>>\n{{registerCustomType \"Swift\"}}{{setCustomType \"Swift\" \"int\" \"Int\"}}{{range .Models}}{{range .Properties}}{{toCustomType \"Swift\" .}} {{toCustomType \"Kotlin\" .}}{{end}}{{end}}\n<<levo>>\n"
+
+func TestTypeRegistry(testing *testing.T) {
+	intProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int"}
+
+	firstRegistry := GetTypeRegistry()
+	secondRegistry := GetTypeRegistry()
+	firstRegistry.SetCustomTypes("Swift", map[string]string{"int": "Int"})
+
+	if !firstRegistry.IsCustomType("Swift", intProp) {
+		testing.Errorf("Expecting int to be a Swift custom type")
+	}
+	if secondRegistry.IsCustomType("Swift", intProp) {
+		testing.Errorf("Custom type leaked between registries")
+	}
+
+	//Mappings for unregistered types are ignored
+	secondRegistry.SetCustomType("Swift", "int", "Int")
+	if secondRegistry.IsCustomType("Swift", intProp) {
+		testing.Errorf("Mapping was added to an unregistered custom type")
+	}
+}
+
+func TestContextCustomTypes(testing *testing.T) {
+	firstContext := BeginContext()
+	firstContext.AddCustomType("Kotlin", map[string]string{"int": "Int"})
+	firstContext.AddTemplate("types.lt", []byte(customTypeTemplateBody), testTemplaterVersion, "", &firstContext.GoAdapter)
+	model, _ := firstContext.AddModelWithName(modelName)
+	model.AddProperty(propertyRemoteIdent, propertyLocalIdent, "int")
+	firstContext.AddTemplatesForModelsMapping([]string{"types.lt"}, []string{modelName})
+
+	generatedFiles, err := ProcessMappings(firstContext)
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(generatedFiles) != 1 || string(generatedFiles[0].Body) != "Int Int" {
+		testing.Errorf("Expecting body %v. Got %v", "Int Int", generatedFiles)
+	}
+
+	//Types registered by the first context's templates stay in that context
+	secondContext := BeginContext()
+	if secondContext.TypeRegistry.IsCustomType("Swift", ModelProperty{PropertyType: "int"}) {
+		testing.Errorf("Custom type registered by a template leaked into another context")
+	}
+	if !firstContext.TypeRegistry.IsCustomType("Swift", ModelProperty{PropertyType: "int"}) {
+		testing.Errorf("Custom type registered by a template is missing from its context")
+	}
+}
+
+func TestDefaultTypeRegistry(testing *testing.T) {
+	intProp := ModelProperty{RemoteIdentifier: "Prop01", PropertyType: "int"}
+	RegisterCustomType("Scala")
+	SetCustomType("Scala", "int", "Int")
+	if scalaType := ToCustomType("Scala", intProp); scalaType != "Int" {
+		testing.Errorf("Expecting %v. Got %v", "Int", scalaType)
+	}
+	if !IsCustomType("Scala", intProp) {
+		testing.Errorf("Expecting int to be a Scala custom type")
+	}
+
+	//Adapters without a TypeRegistry get their own
+	adapter := GoTemplateAdapter{}
+	templateInfo := TemplateInfo{FileName: "scala.lt", Body: []byte("<<levo filename:scala.txt>>\n{{range .Models}}{{range .Properties}}{{toCustomType \"Scala\" .}}{{end}}{{end}}\n<<levo>>\n"), Version: LibraryVersion}
+	if err := adapter.ParseTemplate(templateInfo); err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	generatedFiles, err := adapter.GenerateFiles(templateInfo, TemplateData{Models: []Model{{Name: modelName, Properties: []ModelProperty{intProp}}}})
+	if err != nil {
+		testing.Fatalf("Unexpected error: %v", err.Error())
+	}
+	if len(generatedFiles) != 1 || string(generatedFiles[0].Body) != "int" {
+		testing.Errorf("Expecting body %v. Got %v", "int", generatedFiles)
+	}
+	if adapter.TypeRegistry == defaultTypeRegistry {
+		testing.Errorf("Adapter without a TypeRegistry shares the default one")
+	}
+
+	//Contexts keep their own mappings
+	if BeginContext().TypeRegistry.IsCustomType("Scala", intProp) {
+		testing.Errorf("Custom type leaked from the default registry into a context")
+	}
+}
+
+func TestConcurrentTemplatesCantChangeCustomTypes(testing *testing.T) {
+	context := BeginContext()
+	context.AddTemplate("types.lt", []byte(customTypeTemplateBody), testTemplaterVersion, "", &context.GoAdapter)
+	context.AddTemplate("more-types.lt", []byte(customTypeTemplateBody), testTemplaterVersion, "", &context.GoAdapter)
+	model, _ := context.AddModelWithName(modelName)
+	model.AddProperty(propertyRemoteIdent, propertyLocalIdent, "int")
+	context.AddTemplatesForModelsMapping([]string{"types.lt", "more-types.lt"}, []string{modelName})
+	context.Concurrency = 2
+
+	if _, err := ProcessMappings(context); err == nil || !strings.Contains(err.Error(), errFrozenTypeRegistry.Error()) {
+		testing.Errorf("Expecting %v. Got %v", errFrozenTypeRegistry, err)
+	}
+	if context.TypeRegistry.IsCustomType("Swift", ModelProperty{PropertyType: "int"}) {
+		testing.Errorf("Custom type was registered by a concurrent template")
+	}
+
+	//The registry can change again once the run is over
+	context.Concurrency = 1
+	if _, err := ProcessMappings(context); err != nil {
+		testing.Errorf("Unexpected error: %v", err.Error())
+	}
+}
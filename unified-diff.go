@@ -0,0 +1,214 @@
+/* Copyright (C) 2014 Pivotal Software, Inc.
+
+All rights reserved. This program and the accompanying materials
+are made available under the terms of the under the Apache License,
+Version 2.0 (the "License”); you may not use this file except in compliance
+with the License. You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.*/
+package levo
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+const diffContextLines int = 3
+
+type diffOperation struct {
+	Kind byte
+	Line string
+}
+
+//UnifiedDiff returns the differences between two bodies in the unified
+//format used by diff -u and git. Identical bodies produce an empty string.
+func UnifiedDiff(fromName string, toName string, from []byte, to []byte) string {
+	if bytes.Equal(from, to) {
+		return ""
+	}
+	output := bytes.NewBufferString("")
+	if isBinaryBody(from) || isBinaryBody(to) {
+		fmt.Fprintf(output, "Binary files %v and %v differ\n", fromName, toName)
+		return output.String()
+	}
+
+	operations := diffLines(splitDiffLines(from), splitDiffLines(to))
+	fmt.Fprintf(output, "--- %v\n+++ %v\n", fromName, toName)
+	for _, hunk := range diffHunks(operations) {
+		writeDiffHunk(output, operations, hunk)
+	}
+	return output.String()
+}
+
+func isBinaryBody(body []byte) bool {
+	return bytes.IndexByte(body, 0) >= 0
+}
+
+func splitDiffLines(body []byte) []string {
+	if len(body) == 0 {
+		return []string{}
+	}
+	lines := make([]string, 0)
+	for _, line := range splitLines(body) {
+		if len(line) > 0 {
+			lines = append(lines, string(line))
+		}
+	}
+	return lines
+}
+
+//diffLines finds a shortest edit script between the two sets of lines
+//using Myers' algorithm. The trace keeps only the diagonals each step could
+//reach, so it grows with the square of the number of edits rather than
+//with the length of the files.
+func diffLines(from []string, to []string) []diffOperation {
+	fromLength := len(from)
+	toLength := len(to)
+	maxEdits := fromLength + toLength
+	if maxEdits == 0 {
+		return []diffOperation{}
+	}
+	offset := maxEdits
+	frontier := make([]int, 2*maxEdits+2)
+	trace := make([][]int, 0)
+
+search:
+	for edits := 0; edits <= maxEdits; edits++ {
+		//Diagonals -edits to edits, of which the step reads the inner ones
+		snapshot := make([]int, 2*edits+1)
+		copy(snapshot, frontier[offset-edits:offset+edits+1])
+		trace = append(trace, snapshot)
+		for diagonal := -edits; diagonal <= edits; diagonal += 2 {
+			var x int
+			if diagonal == -edits || (diagonal != edits && frontier[offset+diagonal-1] < frontier[offset+diagonal+1]) {
+				x = frontier[offset+diagonal+1]
+			} else {
+				x = frontier[offset+diagonal-1] + 1
+			}
+			y := x - diagonal
+			for x < fromLength && y < toLength && from[x] == to[y] {
+				x++
+				y++
+			}
+			frontier[offset+diagonal] = x
+			if x >= fromLength && y >= toLength {
+				break search
+			}
+		}
+	}
+
+	reversed := make([]diffOperation, 0, maxEdits)
+	x := fromLength
+	y := toLength
+	for edits := len(trace) - 1; edits > 0; edits-- {
+		snapshot := trace[edits]
+		diagonal := x - y
+		var previousDiagonal int
+		if diagonal == -edits || (diagonal != edits && snapshot[edits+diagonal-1] < snapshot[edits+diagonal+1]) {
+			previousDiagonal = diagonal + 1
+		} else {
+			previousDiagonal = diagonal - 1
+		}
+		previousX := snapshot[edits+previousDiagonal]
+		previousY := previousX - previousDiagonal
+		for x > previousX && y > previousY {
+			x--
+			y--
+			reversed = append(reversed, diffOperation{Kind: ' ', Line: from[x]})
+		}
+		if x == previousX {
+			y--
+			reversed = append(reversed, diffOperation{Kind: '+', Line: to[y]})
+		} else {
+			x--
+			reversed = append(reversed, diffOperation{Kind: '-', Line: from[x]})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, diffOperation{Kind: ' ', Line: from[x]})
+	}
+
+	operations := make([]diffOperation, len(reversed))
+	for index, operation := range reversed {
+		operations[len(reversed)-1-index] = operation
+	}
+	return operations
+}
+
+//diffHunks groups the changed operations, with their surrounding context,
+//into [start, end) ranges of the operation list.
+func diffHunks(operations []diffOperation) [][2]int {
+	hunks := make([][2]int, 0)
+	for index, operation := range operations {
+		if operation.Kind == ' ' {
+			continue
+		}
+		start := index - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		end := index + diffContextLines + 1
+		if end > len(operations) {
+			end = len(operations)
+		}
+		if len(hunks) > 0 && start <= hunks[len(hunks)-1][1] {
+			hunks[len(hunks)-1][1] = end
+		} else {
+			hunks = append(hunks, [2]int{start, end})
+		}
+	}
+	return hunks
+}
+
+func writeDiffHunk(output *bytes.Buffer, operations []diffOperation, hunk [2]int) {
+	fromStart, toStart := 1, 1
+	for _, operation := range operations[:hunk[0]] {
+		if operation.Kind != '+' {
+			fromStart++
+		}
+		if operation.Kind != '-' {
+			toStart++
+		}
+	}
+	fromCount, toCount := 0, 0
+	for _, operation := range operations[hunk[0]:hunk[1]] {
+		if operation.Kind != '+' {
+			fromCount++
+		}
+		if operation.Kind != '-' {
+			toCount++
+		}
+	}
+	//An empty range starts on the line before it
+	if fromCount == 0 {
+		fromStart--
+	}
+	if toCount == 0 {
+		toStart--
+	}
+
+	fmt.Fprintf(output, "@@ -%v +%v @@\n", diffRange(fromStart, fromCount), diffRange(toStart, toCount))
+	for _, operation := range operations[hunk[0]:hunk[1]] {
+		output.WriteByte(operation.Kind)
+		output.WriteString(operation.Line)
+		if !strings.HasSuffix(operation.Line, "\n") {
+			output.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func diffRange(start int, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%v", start)
+	}
+	return fmt.Sprintf("%v,%v", start, count)
+}